@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+var (
+	reBase64         = regexp.MustCompile(`^[A-Za-z0-9+/_-]{4,}={0,2}$`)
+	rePercentEncoded = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+)
+
+// minBase64Length is length starting from which string decoded to binary data
+// is taken for base64, shorter ones are likely words, e.g. "test".
+const minBase64Length = 32
+
+// jwtDateClaims are registered JWT claims holding unix timestamps.
+var jwtDateClaims = []string{"exp", "iat", "nbf", "auth_time"}
+
+// decodedString is string value which is shown as the value it encodes.
+type decodedString struct {
+	encoding string
+	value    any
+}
+
+func (d decodedString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.value)
+}
+
+// parseJSONString decodes JSON document encoded in string. If containerOnly is
+// set, only objects and arrays are decoded, so that strings like "1" or "null"
+// are kept as is.
+func parseJSONString(s string, containerOnly bool) (any, bool) {
+	s = strings.TrimSpace(s)
+	if containerOnly && (s == "" || s[0] != '{' && s[0] != '[') {
+		return nil, false
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// decodeBase64 tries every base64 flavour, padded or not, standard or URL-safe.
+func decodeBase64(s string) ([]byte, bool) {
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.URLEncoding,
+		base64.RawStdEncoding,
+		base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// isBase64 tells whether s, which decodes to b, is base64 rather than word,
+// date or path made of base64 alphabet. Either b is text or s has features
+// such strings lack: padding or length.
+func isBase64(s string, b []byte) bool {
+	return isText(b) ||
+		len(s)%4 == 0 && strings.HasSuffix(s, "=") ||
+		len(s) >= minBase64Length
+}
+
+// isText tells whether b is printable UTF-8 text.
+func isText(b []byte) bool {
+	s := string(b)
+	return utf8.ValidString(s) && strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsPrint(r) && !unicode.IsSpace(r)
+	}) == -1
+}
+
+// fromBytes returns decoded bytes as JSON document, text or hex dump lines if
+// bytes are binary.
+func fromBytes(b []byte) any {
+	s := string(b)
+	if v, ok := parseJSONString(s, true); ok {
+		return v
+	}
+
+	if isText(b) {
+		return s
+	}
+
+	lines := strings.Split(strings.TrimSuffix(hex.Dump(b), "\n"), "\n")
+	res := make([]any, len(lines))
+	for i, line := range lines {
+		res[i] = line
+	}
+	return res
+}
+
+// decodeJWT decodes header and claims of JSON Web Token, signature is left as is.
+func decodeJWT(s string) (any, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	decodePart := func(part string) (map[string]any, bool) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+		if err != nil {
+			return nil, false
+		}
+
+		var v map[string]any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+
+	header, ok := decodePart(parts[0])
+	if !ok {
+		return nil, false
+	}
+	if _, ok := header["alg"]; !ok {
+		return nil, false
+	}
+
+	claims, ok := decodePart(parts[1])
+	if !ok {
+		return nil, false
+	}
+
+	for _, claim := range jwtDateClaims {
+		if ts, ok := claims[claim].(float64); ok {
+			claims[claim] = decodedString{
+				encoding: "date",
+				value:    time.Unix(int64(ts), 0).UTC().Format(time.RFC3339),
+			}
+		}
+	}
+
+	return map[string]any{
+		"header":    header,
+		"claims":    claims,
+		"signature": parts[2],
+	}, true
+}
+
+// decodeString detects encoding of s and decodes it. Tried encodings are JSON,
+// JWT, percent-encoding and base64, in that order.
+func decodeString(s string) (decodedString, bool) {
+	if v, ok := parseJSONString(s, false); ok {
+		return decodedString{
+			encoding: "json",
+			value:    v,
+		}, true
+	}
+
+	if v, ok := decodeJWT(s); ok {
+		return decodedString{
+			encoding: "jwt",
+			value:    v,
+		}, true
+	}
+
+	if rePercentEncoded.MatchString(s) {
+		if unescaped, err := url.QueryUnescape(s); err == nil {
+			return decodedString{
+				encoding: "url",
+				value:    fromBytes([]byte(unescaped)),
+			}, true
+		}
+	}
+
+	if reBase64.MatchString(s) {
+		if b, ok := decodeBase64(s); ok && isBase64(s, b) {
+			return decodedString{
+				encoding: "base64",
+				value:    fromBytes(b),
+			}, true
+		}
+	}
+
+	return decodedString{}, false
+}
+
+// mapStrings returns copy of v with every string replaced by result of f.
+func mapStrings(v any, p jsonPath, f func(jsonPath, string) any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, val := range v {
+			res[k] = mapStrings(val, p.Key(k), f)
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, val := range v {
+			res[i] = mapStrings(val, p.Index(i), f)
+		}
+		return res
+	case string:
+		return f(p, v)
+	default:
+		return v
+	}
+}
+
+// expandJSON recursively replaces strings containing JSON objects or arrays
+// with decoded values. It is available in queries as expandjson builtin.
+func expandJSON(v any) any {
+	var expand func(jsonPath, string) any
+	expand = func(p jsonPath, s string) any {
+		decoded, ok := parseJSONString(s, true)
+		if !ok {
+			return s
+		}
+		return mapStrings(decoded, p, expand)
+	}
+	return mapStrings(v, nil, expand)
+}
+
+// decodeStrings replaces strings, which were decoded by user or contain JSON
+// containers in automatic mode, with their decoded values.
+func (m *model) decodeStrings(v any) any {
+	if len(m.decoded) == 0 && !m.expandStrings {
+		return v
+	}
+
+	var decode func(jsonPath, string) any
+	decode = func(p jsonPath, s string) any {
+		explicit, ok := m.decoded[p.String()]
+		if ok && !explicit || !ok && !m.expandStrings {
+			return s
+		}
+
+		var decoded decodedString
+		if explicit {
+			if decoded, ok = decodeString(s); !ok {
+				return s
+			}
+		} else {
+			v, ok := parseJSONString(s, true)
+			if !ok {
+				return s
+			}
+
+			decoded = decodedString{
+				encoding: "json",
+				value:    v,
+			}
+		}
+
+		decoded.value = mapStrings(decoded.value, p, decode)
+		return decoded
+	}
+	return mapStrings(v, nil, decode)
+}
+
+// newTree builds tree of v, or of its part at zoomed root, pruned to search
+// results if filtering.
+func (m *model) newTree(v any) hierachy.Node[entry] {
+	tree := m.documentTree(v)
+	m.filterTree(&tree)
+	return tree
+}
+
+// documentTree builds whole tree of v, or of its part at zoomed root.
+func (m *model) documentTree(v any) hierachy.Node[entry] {
+	v, _ = m.viewed(v)
+	return fromJSONAt(m.root, v)
+}
+
+// toggleDecoded expands selected string node into decoded subtree or
+// collapses decoded subtree back into string. Only pane in focus is changed,
+// as paths of other one point into different document.
+func (m *model) toggleDecoded() {
+	selected := m.tree.Selected()
+	if selected.decoded == "" && selected.kind != elemKindString {
+		return
+	}
+
+	if s, err := strconv.Unquote(selected.value); err == nil && selected.decoded == "" {
+		if _, ok := decodeString(s); !ok {
+			m.message = "string is not encoded"
+			return
+		}
+	}
+
+	if m.decoded == nil {
+		m.decoded = map[string]bool{}
+	}
+	m.decoded[selected.path.String()] = selected.decoded == ""
+
+	m.rebuildTree()
+}
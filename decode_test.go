@@ -0,0 +1,182 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testJWT = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9" +
+	".eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ" +
+	".SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+
+func TestDecodeString(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]decodedString{
+		`{"a": 1}`:         {encoding: "json", value: map[string]any{"a": 1.0}},
+		`[1, "x"]`:         {encoding: "json", value: []any{1.0, "x"}},
+		"a%20b%3Dc":        {encoding: "url", value: "a b=c"},
+		"aGVsbG8gd29ybGQ=": {encoding: "base64", value: "hello world"},
+		"aGVsbG8":          {encoding: "base64", value: "hello"},
+		"eyJhIjoxfQ":       {encoding: "base64", value: map[string]any{"a": 1.0}},
+		"AAECAwQFBgc=": {encoding: "base64", value: []any{
+			"00000000  00 01 02 03 04 05 06 07                           |........|",
+		}},
+		testJWT: {encoding: "jwt", value: map[string]any{
+			"header": map[string]any{"alg": "HS256", "typ": "JWT"},
+			"claims": map[string]any{
+				"sub":  "1234567890",
+				"name": "John Doe",
+				"iat":  decodedString{encoding: "date", value: "2018-01-18T01:30:22Z"},
+			},
+			"signature": "SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c",
+		}},
+	} {
+		s, want := s, want
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := decodeString(s)
+			if !ok {
+				t.Fatalf("decodeString(%q) failed", s)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeString(%q) = %#v, want %#v", s, got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeStringNotEncoded(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"",
+		"hello world",
+		"test",
+		"John",
+		"abcd",
+		"Lorem",
+		"100%",
+		"2024-01-01",
+		"path/to/file",
+		"my-key",
+	} {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+
+			if got, ok := decodeString(s); ok {
+				t.Errorf("decodeString(%q) = %#v, want not encoded", s, got)
+			}
+		})
+	}
+}
+
+func TestDecodeJWT(t *testing.T) {
+	t.Parallel()
+
+	for name, s := range map[string]string{
+		"two parts":       "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0",
+		"header not json": "aGVsbG8.eyJzdWIiOiIxIn0.sig",
+		"header no alg":   "eyJ0eXAiOiJKV1QifQ.eyJzdWIiOiIxIn0.sig",
+		"claims not json": "eyJhbGciOiJIUzI1NiJ9.aGVsbG8.sig",
+		"not base64":      "a.b.c",
+		"dotted text":     "example.com.au",
+	} {
+		s := s
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, ok := decodeJWT(s); ok {
+				t.Errorf("decodeJWT(%q) = %#v, want not JWT", s, got)
+			}
+		})
+	}
+
+	got, ok := decodeJWT("eyJhbGciOiJub25lIn0.eyJleHAiOjAsIm4iOjF9.")
+	want := map[string]any{
+		"header":    map[string]any{"alg": "none"},
+		"claims":    map[string]any{"exp": decodedString{encoding: "date", value: "1970-01-01T00:00:00Z"}, "n": 1.0},
+		"signature": "",
+	}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeJWT() = %#v, %v, want %#v", got, ok, want)
+	}
+}
+
+func TestParseJSONString(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		s             string
+		containerOnly bool
+		want          any
+		ok            bool
+	}{
+		"object":                {` {"a": [1]} `, true, map[string]any{"a": []any{1.0}}, true},
+		"array":                 {`[1, "x"]`, true, []any{1.0, "x"}, true},
+		"number":                {`1`, false, 1.0, true},
+		"number container only": {`1`, true, nil, false},
+		"null container only":   {`null`, true, nil, false},
+		"invalid":               {`{"a": }`, true, nil, false},
+		"text":                  {`hello`, false, nil, false},
+		"empty":                 {``, true, nil, false},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseJSONString(tc.s, tc.containerOnly)
+			if ok != tc.ok || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseJSONString(%q, %v) = %#v, %v, want %#v, %v", tc.s, tc.containerOnly, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestExpandStrings(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": "{\"b\": \"[1]\"}", "c": "1", "d": "text"}`))
+	m.expandStrings = true
+	m.rebuildTree()
+
+	want := []string{".", ".a", ".a.b", ".a.b[0]", ".a.b/", ".a/", ".c", ".d", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible = %q, want %q", got, want)
+	}
+
+	selectPath(m.tree, jsonPath{"a", "b", 0})
+	if got := m.cursorValue(); got != "1" {
+		t.Errorf("cursorValue() = %q, want 1", got)
+	}
+}
+
+func TestToggleDecoded(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": "eyJiIjoxfQ==", "c": "my-key"}`))
+	selectPath(m.tree, jsonPath{"a"})
+	send(m, keys("x")...)
+	want := []string{".", ".a", ".a.b", ".a/", ".c", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after decoding = %q, want %q", got, want)
+	}
+
+	send(m, keys("x")...)
+	want = []string{".", ".a", ".c", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after encoding back = %q, want %q", got, want)
+	}
+
+	selectPath(m.tree, jsonPath{"c"})
+	send(m, keys("x")...)
+	if m.message != "string is not encoded" {
+		t.Errorf("message = %q, want string is not encoded", m.message)
+	}
+	send(m, keys("j")...)
+	if m.message != "" {
+		t.Errorf("message = %q after next key, want none", m.message)
+	}
+}
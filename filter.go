@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+// filterTree prunes tree to search results and their ancestors if filtering,
+// subtrees of results are kept whole. Paths of nodes are not changed.
+func (m *model) filterTree(tree *hierachy.Node[entry]) {
+	if !m.filter || m.search == nil {
+		return
+	}
+
+	var prune func(node *hierachy.Node[entry]) bool
+	prune = func(node *hierachy.Node[entry]) bool {
+		if _, ok := m.search.byPath[node.Value.path.String()]; ok {
+			return true
+		}
+
+		children := node.Children[:0]
+		for _, child := range node.Children {
+			if prune(&child) {
+				children = append(children, child)
+			}
+		}
+		node.Children = children
+		return len(children) > 0
+	}
+	prune(tree)
+}
+
+// setFilter shows tree pruned to search results or whole tree, keeping cursor.
+// Pruned tree is shown expanded, whole tree gets back folds it had before
+// filtering.
+func (m *model) setFilter(on bool) {
+	state := saveState(m.tree)
+	switch {
+	case on && !m.filter:
+		m.unfiltered = state.collapsed
+	case !on && m.filter:
+		state.collapsed = m.unfiltered
+	}
+	if on {
+		state.collapsed = map[string]struct{}{}
+	}
+
+	m.filter = on
+	m.tree = newHierachy(m.newTree(m.result))
+	restoreState(m.tree, state)
+}
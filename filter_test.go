@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rprtr258/tea"
+)
+
+func TestFilterTree(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"a": {"b": 1, "c": 2}, "d": [1, {"e": 3}], "f": 4}`
+	for name, tc := range map[string]struct {
+		filter  bool
+		results []string
+		want    []string
+	}{
+		"not filtering": {
+			filter:  false,
+			results: []string{".f"},
+			want:    []string{".", ".a", ".a.b", ".a.c", ".a/", ".d", ".d[0]", ".d[1]", ".d[1].e", ".d[1]/", ".d/", ".f", "./"},
+		},
+		"leaf": {
+			filter:  true,
+			results: []string{".a.c"},
+			want:    []string{".", ".a", ".a.c", ".a/", "./"},
+		},
+		"subtree is kept whole": {
+			filter:  true,
+			results: []string{".d"},
+			want:    []string{".", ".d", ".d[0]", ".d[1]", ".d[1].e", ".d[1]/", ".d/", "./"},
+		},
+		"several results": {
+			filter:  true,
+			results: []string{".a.b", ".d[1].e", ".f"},
+			want:    []string{".", ".a", ".a.b", ".a/", ".d", ".d[1]", ".d[1].e", ".d[1]/", ".d/", ".f", "./"},
+		},
+		"no results": {
+			filter:  true,
+			results: nil,
+			want:    []string{"."},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			byPath := map[string][]int{}
+			for i, p := range tc.results {
+				byPath[p] = []int{i}
+			}
+			m := &model{}
+			m.filter = tc.filter
+			m.search = &search{byPath: byPath}
+
+			tree := fromJSON(mustJSON(doc))
+			m.filterTree(&tree)
+			if got := visiblePaths(newHierachy(tree)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("filterTree() shows %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	enter := tea.MsgKey{Type: tea.KeyEnter}
+	m := prepareDocument(t, mustJSON(`{"a": {"b": 1, "c": 2}, "d": [1, {"e": 3}], "f": 4}`))
+	send(m, keys("F")...) // nothing to filter by
+	if m.filter {
+		t.Fatalf("filtering without search")
+	}
+
+	send(m, keys("E/3")...)
+	send(m, enter)
+	unfiltered := []string{".", ".a+", ".d", ".d[0]", ".d[1]", ".d[1].e", ".d[1]/", ".d/", ".f", "./"}
+	for i, tc := range []struct {
+		keys string
+		want []string
+	}{
+		{"F", []string{".", ".d", ".d[1]", ".d[1].e", ".d[1]/", ".d/", "./"}},
+		{"F", unfiltered},
+		{"F", []string{".", ".d", ".d[1]", ".d[1].e", ".d[1]/", ".d/", "./"}},
+	} {
+		send(m, keys(tc.keys)...)
+		if got := visiblePaths(m.tree); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("visible after %d-th step = %q, want %q", i+1, got, tc.want)
+		}
+		if got, want := m.tree.Selected().path, (jsonPath{"d", 1, "e"}); !got.Equal(want) {
+			t.Errorf("cursor after %d-th step = %s, want %s", i+1, got, want)
+		}
+	}
+
+	// new search filters by its results
+	send(m, keys("/")...)
+	send(m, tea.MsgKey{Type: tea.KeyCtrlU})
+	send(m, keys("4")...)
+	send(m, enter)
+	want := []string{".", ".f", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after new search = %q, want %q", got, want)
+	}
+
+	// cancelled search shows whole tree with folds it had before filtering
+	send(m, keys("/")...)
+	send(m, tea.MsgKey{Type: tea.KeyEsc})
+	if m.filter {
+		t.Errorf("filtering after search is cancelled")
+	}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, unfiltered) {
+		t.Errorf("visible after search is cancelled = %q, want %q", got, unfiltered)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+	"github.com/rprtr258/tea/styles"
+)
+
+// gutterMode tells what line numbers are shown left of JSON view.
+type gutterMode int
+
+const (
+	gutterOff gutterMode = iota
+	gutterAbsolute
+	// gutterRelative shows distance to cursor, cursor line has absolute number
+	gutterRelative
+)
+
+// arrayIndex returns label of array element, e.g. [17], or empty string for
+// other nodes.
+func arrayIndex(e entry) string {
+	if len(e.path) == 0 {
+		return ""
+	}
+
+	i, ok := e.path[len(e.path)-1].(int)
+	if !ok {
+		return ""
+	}
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// gutterColumns returns widths of line number and array index columns, index
+// column is omitted if there are no array elements shown.
+func (m *model) gutterColumns() (number, index int) {
+	if m.gutter == gutterOff {
+		return 0, 0
+	}
+
+	n := 0
+	m.tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		n++
+		index = max(index, len(arrayIndex(item.Value)))
+		return true
+	})
+	return len(strconv.Itoa(n)), index
+}
+
+func (m *model) gutterWidth() int {
+	number, index := m.gutterColumns()
+	switch {
+	case m.gutter == gutterOff:
+		return 0
+	case index == 0:
+		return number + 1
+	default:
+		return number + 1 + index + 1
+	}
+}
+
+// jsonWidth is number of columns available for JSON view.
+func (m *model) jsonWidth() int {
+	return max(0, m.paneWidth()-m.gutterWidth())
+}
+
+// viewGutter shows labels of nodes on first lines they take, including
+// pinned sticky ancestors.
+func (m *model) viewGutter(vb tea.Viewbox) {
+	w := m.viewWindow()
+	heights := m.lineHeights(w)
+	cursor := cursorIndex(m.tree)
+	number, index := m.gutterColumns()
+	writeLabel := func(vb tea.Viewbox, i int) {
+		n := i + 1
+		if m.gutter == gutterRelative && i != cursor {
+			n = max(i-cursor, cursor-i)
+		}
+
+		label := fmt.Sprintf("%*d ", number, n)
+		if index > 0 {
+			label += fmt.Sprintf("%*s ", index, arrayIndex(w.item(i).Value))
+		}
+		vb.Styled(styles.Style{}.Foreground(fun.IF(i == cursor, currentTheme.Key, currentTheme.Preview))).WriteLine(label)
+	}
+
+	row := 0
+	for i := m.offset; i < w.end() && row < vb.Height; i++ {
+		writeLabel(vb.Row(row), i)
+		row += heights(i)
+	}
+	for row, i := range m.stickyAncestors(w, heights) {
+		vbRow := vb.Row(row)
+		vbRow.Fill(' ')
+		writeLabel(vbRow, i)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGutter(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		keys string
+		want []string
+	}{
+		"off": {"", []string{"{", `  "a": [`, "    1", "    2", "  ]", `  "b": 3`, "}"}},
+		"absolute": {"#", []string{
+			"1     {",
+			`2       "a": [`,
+			"3 [0]     1",
+			"4 [1]     2",
+			"5       ]",
+			`6       "b": 3`,
+			"7     }",
+		}},
+		"relative": {"#j#", []string{
+			"1     {",
+			`2       "a": [`,
+			"1 [0]     1",
+			"2 [1]     2",
+			"3       ]",
+			`4       "b": 3`,
+			"5     }",
+		}},
+		"off again": {"###", []string{"{", `  "a": [`, "    1", "    2", "  ]", `  "b": 3`, "}"}},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := prepareDocument(t, mustJSON(`{"a": [1, 2], "b": 3}`))
+			send(m, keys(tc.keys)...)
+			if got := screenLines(m)[:len(tc.want)]; !slices.Equal(got, tc.want) {
+				t.Errorf("screen after %q = %q, want %q", tc.keys, got, tc.want)
+			}
+		})
+	}
+}
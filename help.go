@@ -42,12 +42,30 @@ Examples:
   fx data.json          # view JSON
   fx data.json .field   # view JSON field
   curl ... | fx         # view JSON from curl
+  fx --watch 'kubectl get pods -o json' --interval 5s
 
 Flags:
   -h, --help            print help
   --themes              print themes
   -r, --raw             treat input as a raw string
   -s, --slurp           read all inputs into an array
+  --expand-strings      show strings containing JSON as decoded values
+  --watch CMD           rerun shell command and highlight changes of its output
+  --interval DURATION   watch interval, default 2s
+  --query-timeout DURATION
+                        stop queries running longer, default 10s
+  --query-limit N       max number of query results, 0 for no limit,
+                        default 100000
+
+Search:
+  /pattern              regex search in keys and values, case insensitive
+  /pattern/             case sensitive search
+  key:RE value:RE       match only keys or only values
+  value:>1000           compare numbers, also >=, <, <= and =
+  type:null             match nodes of type, e.g. object, array, number
+  in:.items             match nodes inside of subtree
+  &PROGRAM              highlight nodes at paths selected by jq program,
+                        e.g. &.. | select(.status? == "error")
 
 Key bindings:
 %v`,
@@ -1 +1,212 @@
 package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	identifier       = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	identifierPrefix = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+)
+
+// jsonPath is a path from document root to a node, each part is either
+// object key (string) or array index (int), same as gojq paths.
+type jsonPath []any
+
+// closingBracket is the last part of path to closing bracket line of object
+// or array, it is not shown in path string.
+type closingBracket struct{}
+
+// Key returns path to child of object with given key.
+func (p jsonPath) Key(key string) jsonPath {
+	return append(p[:len(p):len(p)], key)
+}
+
+// Index returns path to element of array with given index.
+func (p jsonPath) Index(index int) jsonPath {
+	return append(p[:len(p):len(p)], index)
+}
+
+func (p jsonPath) HasPrefix(prefix jsonPath) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+
+	for i, part := range prefix {
+		if p[i] != part {
+			return false
+		}
+	}
+	return true
+}
+
+func (p jsonPath) Equal(other jsonPath) bool {
+	return len(p) == len(other) && p.HasPrefix(other)
+}
+
+// Closing returns path to closing bracket line of container at path p.
+func (p jsonPath) Closing() jsonPath {
+	return append(p[:len(p):len(p)], closingBracket{})
+}
+
+// Value returns path of value at p, that is container path for path to its
+// closing bracket.
+func (p jsonPath) Value() jsonPath {
+	if len(p) > 0 && p[len(p)-1] == (closingBracket{}) {
+		return p[:len(p)-1]
+	}
+	return p
+}
+
+// String formats path as jq expression, e.g. .items[3]["strange key"]
+func (p jsonPath) String() string {
+	p = p.Value()
+	if len(p) == 0 {
+		return "."
+	}
+
+	var sb strings.Builder
+	for i, part := range p {
+		switch part := part.(type) {
+		case string:
+			if identifier.MatchString(part) {
+				sb.WriteString("." + part)
+				continue
+			}
+
+			if i == 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString("[" + strconv.Quote(part) + "]")
+		case int:
+			if i == 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString("[" + strconv.Itoa(part) + "]")
+		}
+	}
+	return sb.String()
+}
+
+// parsePath parses path formatted as jq expression, e.g. .items[3]["strange key"]
+func parsePath(s string) (jsonPath, error) {
+	if !strings.HasPrefix(s, ".") {
+		return nil, fmt.Errorf("path must start with '.': %s", s)
+	}
+
+	p := jsonPath{}
+	rest := s[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `["`):
+			quoted, err := strconv.QuotedPrefix(rest[1:])
+			if err != nil || !strings.HasPrefix(rest[1+len(quoted):], "]") {
+				return nil, fmt.Errorf("invalid key in path: %s", s)
+			}
+
+			key, _ := strconv.Unquote(quoted)
+			p = p.Key(key)
+			rest = rest[1+len(quoted)+1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed index in path: %s", s)
+			}
+
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index in path: %s", s)
+			}
+
+			p = p.Index(index)
+			rest = rest[end+1:]
+		default:
+			if len(p) > 0 {
+				if !strings.HasPrefix(rest, ".") {
+					return nil, fmt.Errorf("invalid path: %s", s)
+				}
+
+				rest = rest[1:]
+				if strings.HasPrefix(rest, "[") {
+					continue
+				}
+			}
+
+			key := identifierPrefix.FindString(rest)
+			if key == "" {
+				return nil, fmt.Errorf("invalid key in path: %s", s)
+			}
+
+			p = p.Key(key)
+			rest = rest[len(key):]
+		}
+	}
+	return p, nil
+}
+
+// valueAt returns value at path p inside of v, decoded strings on the way are
+// unwrapped.
+func valueAt(v any, p jsonPath) (any, bool) {
+	for _, part := range p {
+		if d, ok := v.(decodedString); ok {
+			v = d.value
+		}
+
+		switch part := part.(type) {
+		case string:
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+
+			if v, ok = obj[part]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := v.([]any)
+			if !ok || part < 0 || part >= len(arr) {
+				return nil, false
+			}
+
+			v = arr[part]
+		}
+	}
+
+	if d, ok := v.(decodedString); ok {
+		v = d.value
+	}
+	return v, true
+}
+
+// stringify returns strings as is and other values as indented JSON.
+func stringify(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// breadcrumbAt returns ancestor of p, whose path string ends at column x of
+// p's path string, so that each part of shown path leads to its node.
+func breadcrumbAt(p jsonPath, x int) (jsonPath, bool) {
+	p = p.Value()
+	if x < 0 || x >= len(p.String()) {
+		return nil, false
+	}
+
+	for i := range p {
+		if len(p[:i].String()) > x {
+			return p[:i], true
+		}
+	}
+	return p, true
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	for s, p := range map[string]jsonPath{
+		".":                 {},
+		".a":                {"a"},
+		".a.b_2":            {"a", "b_2"},
+		".a[3]":             {"a", 3},
+		".[0]":              {0},
+		".[0][1].x":         {0, 1, "x"},
+		`.["strange key"]`:  {"strange key"},
+		`.a["b c"][2]`:      {"a", "b c", 2},
+		`.["quote \" key"]`: {`quote " key`},
+	} {
+		s, p := s, p
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+
+			if got := p.String(); got != s {
+				t.Errorf("String() = %q, want %q", got, s)
+			}
+
+			got, err := parsePath(s)
+			if err != nil {
+				t.Fatalf("parsePath(%q): %v", s, err)
+			}
+			if !reflect.DeepEqual(got, p) {
+				t.Errorf("parsePath(%q) = %#v, want %#v", s, got, p)
+			}
+		})
+	}
+}
+
+func TestJSONPathClosing(t *testing.T) {
+	t.Parallel()
+
+	if got := (jsonPath{"a", 1}).Closing().String(); got != ".a[1]" {
+		t.Errorf("String() of closing bracket = %q, want .a[1]", got)
+	}
+}
+
+func TestParsePathInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"",
+		"a",
+		".a[",
+		".a[x]",
+		`.["key`,
+		`.["key"`,
+		".a b",
+		".a..b",
+	} {
+		s := s
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+
+			if p, err := parsePath(s); err == nil {
+				t.Errorf("parsePath(%q) = %#v, want error", s, p)
+			}
+		})
+	}
+}
@@ -20,12 +20,33 @@ type KeyMap struct {
 	CollapseAll         key.Binding
 	NextSibling         key.Binding
 	PrevSibling         key.Binding
+	GotoParent          key.Binding
+	FirstChild          key.Binding
+	LastChild           key.Binding
+	MatchBracket        key.Binding
 	ToggleWrap          key.Binding
+	ScrollLeft          key.Binding
+	ScrollRight         key.Binding
+	ToggleLineNumbers   key.Binding
 	Yank                key.Binding
 	Search              key.Binding
 	SearchNext          key.Binding
 	SearchPrev          key.Binding
+	SearchPaths         key.Binding
+	Filter              key.Binding
 	Dig                 key.Binding
+	PopStage            key.Binding
+	Picker              key.Binding
+	ZoomIn              key.Binding
+	ZoomOut             key.Binding
+	Decode              key.Binding
+	ToggleSplit         key.Binding
+	SwitchPane          key.Binding
+	Count               key.Binding
+	SetMark             key.Binding
+	GotoMark            key.Binding
+	JumpBack            key.Binding
+	JumpForward         key.Binding
 }
 
 var keyMap = KeyMap{
@@ -51,11 +72,11 @@ var keyMap = KeyMap{
 	},
 	GotoTop: key.Binding{
 		Keys: []string{"g", "home"},
-		Help: key.Help{"", "goto top"},
+		Help: key.Help{"gg, home", "goto top, or line given by count"},
 	},
 	GotoBottom: key.Binding{
 		Keys: []string{"G", "end"},
-		Help: key.Help{"", "goto bottom"},
+		Help: key.Help{"", "goto bottom, or line given by count"},
 	},
 	Down: key.Binding{
 		Keys: []string{"down", "j"},
@@ -87,7 +108,7 @@ var keyMap = KeyMap{
 	},
 	CollapseAll: key.Binding{
 		Keys: []string{"E"},
-		Help: key.Help{"", "collapse all"},
+		Help: key.Help{"", "collapse all, or nodes deeper than count"},
 	},
 	NextSibling: key.Binding{
 		Keys: []string{"J", "shift+down"},
@@ -97,9 +118,37 @@ var keyMap = KeyMap{
 		Keys: []string{"K", "shift+up"},
 		Help: key.Help{"", "previous sibling"},
 	},
+	GotoParent: key.Binding{
+		Keys: []string{"p"},
+		Help: key.Help{"", "goto parent"},
+	},
+	FirstChild: key.Binding{
+		Keys: []string{"["},
+		Help: key.Help{"", "goto first child"},
+	},
+	LastChild: key.Binding{
+		Keys: []string{"]"},
+		Help: key.Help{"", "goto last child"},
+	},
+	MatchBracket: key.Binding{
+		Keys: []string{"%"},
+		Help: key.Help{"", "goto matching bracket"},
+	},
 	ToggleWrap: key.Binding{
 		Keys: []string{"z"},
-		Help: key.Help{"", "toggle strings wrap"},
+		Help: key.Help{"zw", "toggle strings wrap"},
+	},
+	ScrollLeft: key.Binding{
+		Keys: []string{"<"},
+		Help: key.Help{"zh, <", "scroll left, alt+wheel up as shift is not reported"},
+	},
+	ScrollRight: key.Binding{
+		Keys: []string{">"},
+		Help: key.Help{"zl, >", "scroll right, alt+wheel down as shift is not reported"},
+	},
+	ToggleLineNumbers: key.Binding{
+		Keys: []string{"#"},
+		Help: key.Help{"", "show absolute, relative or no line numbers"},
 	},
 	Yank: key.Binding{
 		Keys: []string{"y"},
@@ -117,10 +166,66 @@ var keyMap = KeyMap{
 		Keys: []string{"N"},
 		Help: key.Help{"", "prev search result"},
 	},
+	SearchPaths: key.Binding{
+		Keys: []string{"&"},
+		Help: key.Help{"", "highlight paths selected by jq program"},
+	},
+	Filter: key.Binding{
+		Keys: []string{"F"},
+		Help: key.Help{"", "show only search results"},
+	},
 	Dig: key.Binding{
 		Keys: []string{"."},
 		Help: key.Help{"", "dig json"},
 	},
+	PopStage: key.Binding{
+		Keys: []string{"U"},
+		Help: key.Help{"", "undo last query, showing previous stage of pipeline"},
+	},
+	Picker: key.Binding{
+		Keys: []string{"ctrl+p"},
+		Help: key.Help{"", "fuzzy find path"},
+	},
+	ZoomIn: key.Binding{
+		Keys: []string{"+"},
+		Help: key.Help{"", "zoom into selected node"},
+	},
+	ZoomOut: key.Binding{
+		Keys: []string{"-"},
+		Help: key.Help{"", "zoom out to previous root"},
+	},
+	Decode: key.Binding{
+		Keys: []string{"x"},
+		Help: key.Help{"", "decode/encode string with JSON, JWT, base64 or URL encoding"},
+	},
+	ToggleSplit: key.Binding{
+		Keys: []string{"s"},
+		Help: key.Help{"", "show original document next to query result"},
+	},
+	SwitchPane: key.Binding{
+		Keys: []string{"w", "ctrl+w"},
+		Help: key.Help{"w, ctrl+w", "switch between original document and query result"},
+	},
+	Count: key.Binding{
+		Keys: []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"},
+		Help: key.Help{"0-9", "repeat next motion count times, NE folds to level N"},
+	},
+	SetMark: key.Binding{
+		Keys: []string{"m"},
+		Help: key.Help{"m{a-z}", "set mark"},
+	},
+	GotoMark: key.Binding{
+		Keys: []string{"'"},
+		Help: key.Help{"'{a-z}", "goto mark"},
+	},
+	JumpBack: key.Binding{
+		Keys: []string{"ctrl+o"},
+		Help: key.Help{"", "goto previous position in jump list"},
+	},
+	JumpForward: key.Binding{
+		Keys: []string{"ctrl+i", "tab"},
+		Help: key.Help{"", "goto next position in jump list"},
+	},
 }
 
 var (
@@ -7,8 +7,14 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/itchyny/gojq"
 	"github.com/mattn/go-isatty"
 	"github.com/rprtr258/fun"
@@ -32,75 +38,69 @@ const (
 	elemKindBool
 )
 
+type diffKind int
+
+const (
+	diffNone diffKind = iota
+	diffAdded
+	diffRemoved
+	diffChanged
+)
+
 type entry struct {
 	kind  elemKind
 	isKey bool
 	key   string
 	value string
+	path  jsonPath
+	diff  diffKind
+	// decoded is encoding of string the node was decoded from, if any
+	decoded string
+	// closing is set for closing bracket line of object or array
+	closing bool
+	// size is length of value serialized as compact JSON
+	size int
+	// count is number of children of object or array
+	count int
+	// preview is short form of first children shown for collapsed node
+	preview []string
 }
 
 func fromJSON(v any) hierachy.Node[entry] {
+	return fromJSONAt(nil, v)
+}
+
+func fromJSONAt(p jsonPath, v any) hierachy.Node[entry] {
+	var res hierachy.Node[entry]
 	switch v := v.(type) {
+	case decodedString:
+		res = fromJSONAt(p, v.value)
+		res.Value.decoded = v.encoding
+		return res
 	case map[string]any:
-		return hierachy.Node[entry]{
+		keys := fun.Keys(v)
+		sort.Strings(keys)
+		res = hierachy.Node[entry]{
 			Value: entry{
 				kind:  elemKindObject,
 				value: "{}",
 			},
-			Children: fun.MapToSlice(v, func(k string, val any) hierachy.Node[entry] {
-				children := []any{val}
-				value := ""
-				var kind elemKind
-				switch val := val.(type) {
-				case map[string]any:
-					value = "{}"
-					res := fromJSON(val)
-					res.Value.isKey = true
-					res.Value.key = strconv.Quote(k)
-					return res
-				case []any:
-					value = "[]"
-					children = val
-					kind = elemKindArray
-				case string:
-					value = strconv.Quote(val)
-					children = nil
-					kind = elemKindString
-				case float64:
-					value = fmt.Sprintf("%#v", val)
-					children = nil
-					kind = elemKindNumber
-				case bool:
-					value = fmt.Sprint(val)
-					children = nil
-					kind = elemKindBool
-				case nil:
-					value = "null"
-					children = nil
-					kind = elemKindNull
-				}
-
-				return hierachy.Node[entry]{
-					Value: entry{
-						kind:  kind,
-						isKey: true,
-						key:   strconv.Quote(k),
-						value: value,
-					},
-					Children: fun.Map[hierachy.Node[entry]](fromJSON, children...),
-				}
-			}),
+			Children: fun.Map[hierachy.Node[entry]](func(k string) hierachy.Node[entry] {
+				return fromJSONAt(p.Key(k), v[k])
+			}, keys...),
 		}
 	case []any:
-		return hierachy.Node[entry]{
+		res = hierachy.Node[entry]{
 			Value: entry{
 				kind:  elemKindArray,
 				value: "[]",
 			},
-			Children: fun.Map[hierachy.Node[entry]](fromJSON, v...),
+			Children: fun.Map[hierachy.Node[entry]](func(val any, i int) hierachy.Node[entry] {
+				return fromJSONAt(p.Index(i), val)
+			}, v...),
 		}
 	case float64, int:
-		return hierachy.Node[entry]{
+		res = hierachy.Node[entry]{
 			Value: entry{
 				kind:  elemKindNumber,
 				value: fmt.Sprintf("%#v", v),
@@ -108,7 +108,7 @@ func fromJSON(v any) hierachy.Node[entry] {
 			Children: nil,
 		}
 	case string:
-		return hierachy.Node[entry]{
+		res = hierachy.Node[entry]{
 			Value: entry{
 				kind:  elemKindString,
 				value: strconv.Quote(v),
@@ -116,7 +116,7 @@ func fromJSON(v any) hierachy.Node[entry] {
 			Children: nil,
 		}
 	case bool:
-		return hierachy.Node[entry]{
+		res = hierachy.Node[entry]{
 			Value: entry{
 				kind:  elemKindBool,
 				value: fmt.Sprint(v),
@@ -124,7 +124,7 @@ func fromJSON(v any) hierachy.Node[entry] {
 			Children: nil,
 		}
 	case nil:
-		return hierachy.Node[entry]{
+		res = hierachy.Node[entry]{
 			Value: entry{
 				kind:  elemKindNull,
 				value: "null",
@@ -134,1054 +134,833 @@ func fromJSON(v any) hierachy.Node[entry] {
 	default:
 		panic(fmt.Sprintf("unexpected token, type=%[1]T, value=%[1]v", v))
 	}
+
+	res.Value.path = p
+	if len(p) > 0 {
+		if k, ok := p[len(p)-1].(string); ok {
+			res.Value.isKey = true
+			res.Value.key = strconv.Quote(k)
+		}
+	}
+	summarize(&res)
+	return res
 }
 
 type model struct {
-	tree     *hierachy.Hierachy[entry]
-	digInput textinput.Model
+	// stage is last stage of query pipeline, whose result is shown, stages
+	// are previous ones
+	stage
+	stages []stage
+	// pane of stage is JSON view in focus, other is the second one shown in
+	// split view
+	other pane
+	// split is whether original document and query result are shown side by
+	// side, sourceFocused is whether pane in focus is one of original
+	split         bool
+	sourceFocused bool
+
+	digInput    textinput.Model
+	searchInput textinput.Model
+	// searchPaths is whether search input is jq program selecting paths to
+	// highlight, e.g. .. | select(.status? == "error"), instead of regex
+	searchPaths bool
+	// picker is fuzzy path picker shown over JSON view, nil if closed
+	picker *picker
+
+	// fileName is name of input shown in status bar
+	fileName   string
+	original   any
+	queryError string
+	// queryID identifies latest dig input, so that results of older runs are
+	// dropped, cancelQuery stops running one started at queryStart, queryEnd
+	// is when last one has finished. Fetching results fails if it takes longer
+	// than queryTimeout, at most queryLimit of them are fetched.
+	queryID      int
+	cancelQuery  context.CancelFunc
+	queryStart   time.Time
+	queryEnd     time.Time
+	queryTimeout time.Duration
+	queryLimit   int
+
+	// watchCommand is rerun every watchInterval to replace original document,
+	// watch mode is off if it is empty
+	watchCommand  string
+	watchInterval time.Duration
+
+	// expandStrings is whether strings containing JSON are shown decoded,
+	// unless pane tells otherwise
+	expandStrings bool
+
+	// message is shown in place of error until next key is pressed
+	message string
+
+	yank bool
+	// wrap tells whether long values are wrapped, otherwise lines can be
+	// scrolled horizontally
+	wrap   bool
+	gutter gutterMode
+
+	termWidth, termHeight int
+
+	// count is number typed before motion, pending is m or ' waiting for
+	// mark name, or g or z waiting for second key
+	count   int
+	pending string
+	marks   map[rune]jsonPath
+	// jumps is history of positions before large jumps, jump is index of
+	// position to return to, or len(jumps) if not traveling through history
+	jumps []jsonPath
+	jump  int
+}
 
-	original, result any
-	queryError       string
+func compileQuery(program string) (*gojq.Code, error) {
+	q, err := gojq.Parse(program)
+	if err != nil {
+		return nil, err
+	}
+
+	return gojq.Compile(q, gojq.WithFunction("expandjson", 0, 0, func(v any, _ []any) any {
+		return expandJSON(v)
+	}))
 }
 
-func (m *model) Init(yield func(...tea.Cmd)) {}
+func (m *model) Init(yield func(...tea.Cmd)) {
+	if m.watchCommand != "" {
+		yield(m.cmdWatch())
+	}
+}
 
 func (m *model) Update(msg tea.Msg, yield func(...tea.Cmd)) {
+	defer m.syncSource(yield)
+	defer m.scrollIntoView()
+
 	switch msg := msg.(type) {
-	case tea.MsgKey:
-		if msg.Type == tea.KeyEsc || msg.Type == tea.KeyEnter || msg.String() == "ctrl+[" {
-			m.digInput.Blur()
-			q, err := gojq.Parse(m.digInput.Value())
-			if err != nil {
-				m.queryError = err.Error()
+	case msgWatch:
+		// next run replays as many results as are shown after this one
+		defer func() { yield(m.cmdWatch()) }()
+		if msg.err != nil {
+			m.queryError = msg.err.Error()
+			return
+		}
+
+		inputs := make([]any, len(m.stages)+1)
+		for i := range inputs {
+			inputs[i] = m.stageInput(i)
+		}
+		m.original = msg.original
+		if m.split {
+			m.inSourcePane(func() {
+				m.reloadSource()
+				m.rerunPathSearch(yield)
+			})
+		}
+		if !slices.Equal(msg.pipeline, m.pipeline()) { // query has changed while command was running
+			return
+		}
+
+		// previous stages are rebuilt when shown again
+		for i := range m.stages {
+			if m.stages[i].stream != nil {
+				m.stages[i].stream.cancel()
+			}
+			m.stages[i].result = msg.results[i]
+			m.stages[i].stream = msg.streams[i]
+			if m.stages[i].stream != nil {
+				m.stages[i].stream.pause()
+			}
+		}
+		// origins are computed again only for stages whose input has changed
+		for i := range inputs {
+			if reflect.DeepEqual(inputs[i], m.stageInput(i)) {
+				continue
+			}
+			if i < len(m.stages) {
+				m.stages[i].stopOrigins()
 			} else {
-				m.queryError = ""
-				iterr := q.Run(m.original)
-				res := []any{}
-				for {
-					v, ok := iterr.Next()
-					if !ok {
-						break
-					}
-
-					if err, ok := v.(error); ok {
-						m.queryError = "execute: " + err.Error()
-						break
-					}
-					res = append(res, v)
-				}
-				m.result = res
-				if len(res) == 1 {
-					m.result = res[0]
-				}
-				m.tree = hierachy.New(fromJSON(m.result))
+				m.stopOrigins()
 			}
-		} else if m.digInput.Focused() {
-			m.digInput.Update(msg, yield)
+		}
+
+		// page of replaced stream could be being fetched, preview of dig input
+		// is run again over changed input
+		m.stopQuery()
+		if m.digInput.Focused() {
+			m.previewQuery(yield)
+		}
+		m.inResultPane(func() {
+			m.stopStream()
+			m.stream = msg.streams[len(m.stages)]
+			result := msg.results[len(m.stages)]
+			m.queryError = ""
+			state := saveState(m.tree)
+			if _, ok := m.viewed(result); !ok { // zoomed root is gone
+				m.resetZoom()
+			}
+			// diff is marked on whole tree, so that hidden nodes are not shown as removed
+			tree := m.documentTree(result)
+			prev, _ := m.viewed(m.result)
+			markDiff(&tree, prev)
+			m.result = result
+			m.redoSearch()
+			m.filterTree(&tree)
+			m.tree = newHierachy(tree)
+			restoreState(m.tree, state)
+			m.rerunPathSearch(yield)
+		})
+	case msgOrigins:
+		m.handleOrigins(msg)
+	case msgSearchPaths:
+		m.handleSearchPaths(msg)
+	case msgQueryDebounce:
+		if msg.id == m.queryID && m.digInput.Focused() {
+			m.startQuery(m.digInput.Value(), yield)
+		}
+	case msgQuerySpinner:
+		if msg.id == m.queryID && m.cancelQuery != nil {
+			yield(m.cmdSpinner())
+		}
+	case msgQueryResult:
+		m.inResultPane(func() {
+			m.handleQueryResult(msg)
+			m.fetchMore(yield)
+		})
+	case msgQueryPage:
+		m.inResultPane(func() {
+			m.handleQueryPage(msg)
+			// keep fetching only until list fills view, then wait for cursor
+			if visibleCount(m.tree) <= 2*m.viewHeight() {
+				m.fetchMore(yield)
+			}
+		})
+	case tea.MsgWindowSize:
+		m.termWidth, m.termHeight = msg.Width, msg.Height
+	case msgPickerIndex:
+		if msg.picker == m.picker { // picker was not closed or reopened
+			m.indexPicker(yield)
+		}
+	case tea.MsgMouse:
+		if m.picker == nil {
+			m.handleMouse(msg)
+		}
+		m.fetchMore(yield)
+	case tea.MsgKey:
+		m.message = ""
+		switch {
+		case m.cancelQuery != nil && (msg.String() == "ctrl+c" || msg.Type == tea.KeyEsc && !m.digInput.Focused()):
+			m.stopQuery()
+			m.queryError = "query cancelled"
+			if !m.digInput.Focused() { // stage of applied query is left empty
+				m.inResultPane(m.discardEmptyStage)
+			}
+		case m.searchRunning() && (msg.String() == "ctrl+c" || msg.Type == tea.KeyEsc && !m.searchInput.Focused()):
+			m.stopSearch()
+		case m.picker != nil:
+			m.handlePickerKey(msg, yield)
+		case m.digInput.Focused():
+			m.handleDigKey(msg, yield)
+		case m.searchInput.Focused():
+			m.handleSearchKey(msg, yield)
+		case m.yank:
+			m.handleYankKey(msg)
+		case m.pending != "":
+			m.handlePendingKey(msg)
+		default:
+			m.handleKey(msg, yield)
+		}
+		m.fetchMore(yield)
+	}
+}
+
+func (m *model) handleDigKey(msg tea.MsgKey, yield func(...tea.Cmd)) {
+	if msg.Type != tea.KeyEsc && msg.Type != tea.KeyEnter && msg.String() != "ctrl+[" {
+		value := m.digInput.Value()
+		m.digInput.Update(msg, yield)
+		if m.digInput.Value() != value {
+			m.previewQuery(yield)
+		}
+		return
+	}
+
+	m.digInput.Blur()
+	if msg.Type != tea.KeyEnter && m.cancelQuery != nil { // shown result is kept
+		m.stopQuery()
+		m.queryError = "query cancelled"
+		m.discardEmptyStage()
+		return
+	}
+
+	m.stopQuery()
+	if m.digInput.Value() == m.query { // already shown by preview
+		m.queryError = ""
+		m.discardEmptyStage()
+		return
+	}
+
+	if !m.startQuery(m.digInput.Value(), yield) {
+		m.discardEmptyStage()
+	}
+}
+
+func (m *model) handleKey(msg tea.MsgKey, yield func(...tea.Cmd)) {
+	if key.Matches(msg, keyMap.Count) && (msg.String() != "0" || m.count > 0) {
+		m.count = min(m.count*10+int(msg.Runes[0]-'0'), maxCount)
+		return
+	}
+
+	// count is number of times motion is repeated, if given
+	count := m.count
+	n := max(1, count)
+	m.count = 0
+
+	switch {
+	case key.Matches(msg, keyMap.Quit):
+		if msg.Type == tea.KeyEsc && time.Since(m.queryEnd) < cancelGrace { // meant to cancel query which has just finished
 			return
 		}
+		yield(tea.Quit)
+
+	case key.Matches(msg, keyMap.Up):
+		moveCursor(m.tree, -n)
+
+	case key.Matches(msg, keyMap.Down):
+		moveCursor(m.tree, n)
+
+	case key.Matches(msg, keyMap.PageUp):
+		m.scroll(-n * m.viewHeight())
+
+	case key.Matches(msg, keyMap.PageDown):
+		m.scroll(n * m.viewHeight())
 
-		if key.Matches(msg, keyMap.Dig) {
-			m.digInput.CursorEnd()
-			m.digInput.Focus()
+	case key.Matches(msg, keyMap.HalfPageUp):
+		m.scroll(-n * m.viewHeight() / 2)
+
+	case key.Matches(msg, keyMap.HalfPageDown):
+		m.scroll(n * m.viewHeight() / 2)
+
+	case key.Matches(msg, keyMap.GotoTop) && msg.String() == "g":
+		// count is kept for second g
+		m.pending, m.count = "g", count
+
+	case key.Matches(msg, keyMap.GotoTop):
+		m.pushJump()
+		m.gotoLine(n)
+
+	case key.Matches(msg, keyMap.GotoBottom):
+		m.pushJump()
+		m.gotoLine(fun.IF(count > 0, count, visibleCount(m.tree)))
+
+	case key.Matches(msg, keyMap.Collapse):
+		if m.tree.IsCollapsed() {
+			m.tree.GoUp()
+		} else {
+			m.tree.ToggleCollapsed()
 		}
 
-		switch msg.String() {
-		case "ctrl+c", "q": // TODO: constants in tea package
-			yield(tea.Quit)
-		case "j":
-			m.tree.GoNextOrUp()
-		case "k":
-			m.tree.GoPrevOrUp()
-		case "h":
-			if m.tree.IsCollapsed() {
-				m.tree.GoUp()
-			} else {
-				m.tree.ToggleCollapsed()
+	case key.Matches(msg, keyMap.Expand):
+		if m.tree.IsCollapsed() {
+			m.tree.ToggleCollapsed()
+		} else {
+			m.tree.GoDown()
+		}
+
+	case key.Matches(msg, keyMap.ExpandRecursively):
+		expandRecursively(m.tree)
+
+	case key.Matches(msg, keyMap.CollapseRecursively):
+		collapseRecursively(m.tree, 1)
+
+	case key.Matches(msg, keyMap.ExpandAll):
+		cursor := m.tree.Selected().path
+		moveCursor(m.tree, -cursorIndex(m.tree))
+		expandRecursively(m.tree)
+		selectPath(m.tree, cursor)
+
+	case key.Matches(msg, keyMap.CollapseAll):
+		m.foldToLevel(n)
+
+	case key.Matches(msg, keyMap.NextSibling):
+		for i := 0; i < n; i++ {
+			m.gotoSibling(1)
+		}
+
+	case key.Matches(msg, keyMap.PrevSibling):
+		for i := 0; i < n; i++ {
+			m.gotoSibling(-1)
+		}
+
+	case key.Matches(msg, keyMap.GotoParent):
+		for i := 0; i < n; i++ {
+			m.tree.GoUp()
+		}
+
+	case key.Matches(msg, keyMap.FirstChild):
+		if m.tree.IsCollapsed() {
+			m.tree.ToggleCollapsed()
+		}
+		m.tree.GoDown()
+
+	case key.Matches(msg, keyMap.LastChild):
+		if m.tree.IsCollapsed() {
+			m.tree.ToggleCollapsed()
+		}
+		items, cursor := visibleItems(m.tree), cursorIndex(m.tree)
+		if end := subtreeEnd(items, cursor); end > cursor {
+			if last := siblingIndex(items, end, -1); last != -1 {
+				moveCursor(m.tree, last-cursor)
 			}
-		case "l":
-			if m.tree.IsCollapsed() {
+		}
+
+	case key.Matches(msg, keyMap.MatchBracket):
+		m.pushJump()
+		if m.tree.Selected().closing {
+			m.tree.GoUp()
+		} else if !m.tree.IsCollapsed() {
+			items, cursor := visibleItems(m.tree), cursorIndex(m.tree)
+			moveCursor(m.tree, subtreeEnd(items, cursor)-cursor)
+		}
+
+	case key.Matches(msg, keyMap.Yank):
+		m.yank = true
+
+	case key.Matches(msg, keyMap.ToggleWrap):
+		// count is kept for zh and zl
+		m.pending, m.count = "z", count
+
+	case key.Matches(msg, keyMap.ToggleLineNumbers):
+		m.gutter = (m.gutter + 1) % 3
+
+	case key.Matches(msg, keyMap.ScrollLeft):
+		m.scrollColumns(-n * scrollStep)
+
+	case key.Matches(msg, keyMap.ScrollRight):
+		m.scrollColumns(n * scrollStep)
+
+	case key.Matches(msg, keyMap.SetMark), key.Matches(msg, keyMap.GotoMark):
+		m.pending = msg.String()
+
+	case key.Matches(msg, keyMap.JumpBack):
+		m.jumpBack()
+
+	case key.Matches(msg, keyMap.JumpForward):
+		m.jumpForward()
+
+	case key.Matches(msg, keyMap.ZoomIn):
+		m.zoomIn()
+
+	case key.Matches(msg, keyMap.ZoomOut):
+		m.zoomOut(len(m.zoom) - 1)
+
+	case key.Matches(msg, keyMap.Dig):
+		if m.sourceFocused { // query changes result pane
+			m.swapPanes()
+		}
+		m.pushStage()
+		m.digInput.SetValue(".")
+		m.digInput.CursorEnd()
+		m.digInput.Focus()
+
+	case key.Matches(msg, keyMap.PopStage):
+		m.inResultPane(func() {
+			m.popStage(len(m.stages) - 1)
+		})
+
+	case key.Matches(msg, keyMap.Picker):
+		m.openPicker(yield)
+
+	case key.Matches(msg, keyMap.Search):
+		m.openSearch(false)
+
+	case key.Matches(msg, keyMap.SearchPaths):
+		m.openSearch(true)
+
+	case key.Matches(msg, keyMap.SearchNext):
+		if m.search != nil {
+			m.selectSearchResult(m.search.cursor + n)
+		}
+
+	case key.Matches(msg, keyMap.SearchPrev):
+		if m.search != nil {
+			m.selectSearchResult(m.search.cursor - n)
+		}
+
+	case key.Matches(msg, keyMap.Filter):
+		if m.search != nil {
+			m.setFilter(!m.filter)
+		}
+
+	case key.Matches(msg, keyMap.Decode):
+		m.toggleDecoded()
+
+	case key.Matches(msg, keyMap.ToggleSplit):
+		m.toggleSplit()
+
+	case key.Matches(msg, keyMap.SwitchPane):
+		if m.split {
+			m.swapPanes()
+		}
+	}
+}
+
+// foldToLevel collapses every node deeper than given level, so that only
+// that many levels of nesting are shown.
+func (m *model) foldToLevel(level int) {
+	cursor := m.tree.Selected().path
+	moveCursor(m.tree, -cursorIndex(m.tree))
+	collapseRecursively(m.tree, level)
+	selectClosest(m.tree, cursor)
+}
+
+// gotoSibling moves cursor to next sibling, or previous one if dir is negative.
+func (m *model) gotoSibling(dir int) {
+	items, cursor := visibleItems(m.tree), cursorIndex(m.tree)
+	if sibling := siblingIndex(items, cursor, dir); sibling != -1 {
+		moveCursor(m.tree, sibling-cursor)
+	}
+}
+
+// scroll moves both viewport and cursor by given number of lines.
+func (m *model) scroll(lines int) {
+	m.offset += lines
+	moveCursor(m.tree, lines)
+}
+
+// scrollIntoView adjusts viewport so that cursor is visible and viewport is
+// filled with nodes as much as possible.
+func (m *model) scrollIntoView() {
+	height, cursor := m.viewHeight(), cursorIndex(m.tree)
+	// offset is moved by at most few screens around cursor below, so only
+	// those nodes are laid out
+	w := visibleWindow(m.tree, max(0, cursor-4*height), cursor+2*height+1)
+	heights := m.lineHeights(w)
+
+	// first node from which cursor still fits into view, heights are
+	// computed only for nodes within one screen above cursor
+	first, lines := cursor, heights(cursor)
+	for first > 0 && lines+heights(first-1) <= height {
+		first--
+		lines += heights(first)
+	}
+	m.offset = fun.Clamp(m.offset, first, cursor)
+
+	// lines taken by nodes from offset to the end, counted until view is full
+	lines = 0
+	for i := m.offset; i < w.end() && lines <= height; i++ {
+		lines += heights(i)
+	}
+	for m.offset > 0 && lines+heights(m.offset-1) <= height {
+		m.offset--
+		lines += heights(m.offset)
+	}
+	// cursor must not be covered by sticky ancestors
+	for m.offset > 0 && cursor < m.nodeAtLine(w, heights, len(m.stickyAncestors(w, heights))) {
+		m.offset--
+	}
+}
+
+func (m *model) handleMouse(msg tea.MsgMouse) {
+	// in split view wheel scrolls pane under pointer, click focuses it
+	if y := msg.Y - m.headerHeight(); m.split && msg.Type != tea.MouseLeft && y >= 0 && y < m.areaHeight() {
+		if source, _, _, ok := m.paneAt(msg.X, y); ok && source != m.sourceFocused {
+			m.inOtherPane(func() { m.handleMouse(msg) })
+			return
+		}
+	}
+
+	// terminals do not report shift with wheel, so alt is used for horizontal
+	// scrolling instead
+	switch {
+	case msg.Type == tea.MouseWheelUp && msg.Alt:
+		m.scrollColumns(-scrollStep)
+	case msg.Type == tea.MouseWheelDown && msg.Alt:
+		m.scrollColumns(scrollStep)
+	case msg.Type == tea.MouseWheelUp:
+		m.scroll(-1)
+	case msg.Type == tea.MouseWheelDown:
+		m.scroll(1)
+	case msg.Type == tea.MouseLeft:
+		if m.digInput.Focused() {
+			break
+		}
+
+		switch y := msg.Y - m.headerHeight(); {
+		case y < 0 && len(m.stages) > 0 && msg.Y == 0: // query pipeline
+			if i, ok := m.pipelineStage(msg.X); ok {
+				m.inResultPane(func() {
+					m.popStage(i)
+				})
+			}
+		case y < 0:
+			if level, ok := m.breadcrumbLevel(msg.X); ok {
+				m.zoomOut(level)
+			}
+		case y < m.areaHeight():
+			if m.split {
+				source, _, py, ok := m.paneAt(msg.X, y)
+				if !ok {
+					break
+				}
+				if source != m.sourceFocused {
+					m.swapPanes()
+				}
+				y = py
+			}
+
+			w := m.viewWindow()
+			heights := m.lineHeights(w)
+			if sticky := m.stickyAncestors(w, heights); y < len(sticky) {
+				selectPath(m.tree, w.item(sticky[y]).Value.path)
+				break
+			}
+
+			line := m.nodeAtLine(w, heights, y)
+			if line == -1 {
+				break
+			}
+
+			if line == cursorIndex(m.tree) {
 				m.tree.ToggleCollapsed()
 			} else {
-				m.tree.GoDown()
+				moveCursor(m.tree, line-cursorIndex(m.tree))
+			}
+		case y == m.areaHeight(): // status line
+			if p, ok := breadcrumbAt(m.tree.Selected().path, msg.X); ok {
+				selectPath(m.tree, p)
 			}
 		}
 	}
 }
 
-func (m *model) viewJSON(vb tea.Viewbox) {
-	selected := 0
-	m.tree.Iter(func(i hierachy.IterItem[entry]) bool {
-		selected++
-		return !i.IsSelected
-	})
+func (m *model) handleYankKey(msg tea.MsgKey) {
+	switch {
+	case key.Matches(msg, yankPath):
+		_ = clipboard.WriteAll(m.tree.Selected().path.String())
+	case key.Matches(msg, yankKey):
+		_ = clipboard.WriteAll(m.cursorKey())
+	case key.Matches(msg, yankValue):
+		_ = clipboard.WriteAll(m.cursorValue())
+	}
+	m.yank = false
+}
+
+func (m *model) cursorKey() string {
+	p := m.tree.Selected().path.Value()
+	if len(p) == 0 {
+		return ""
+	}
+	return fmt.Sprint(p[len(p)-1])
+}
 
+// cursorValue returns selected value, decoded one if string was decoded.
+func (m *model) cursorValue() string {
+	selected := m.tree.Selected()
+	v, ok := valueAt(m.decodeStrings(m.result), selected.path.Value())
+	if !ok { // e.g. value removed since previous watch run
+		return selected.value
+	}
+	return stringify(v)
+}
+
+func (m *model) viewJSON(vb tea.Viewbox) {
 	height := vb.Height
-	iter.Skip(m.tree.Iter, max(0, selected-height/2))(func(i hierachy.IterItem[entry]) bool {
-		vbItem := vb.Row(0)
+	// nextLine moves vb past lines taken by node, returns whether space is left
+	nextLine := func(l layout) bool {
+		height -= l.height()
+		if height <= 0 {
+			return false
+		}
+
+		vb = vb.PaddingTop(l.height())
+		return true
+	}
+	iter.Skip(m.tree.Iter, m.offset)(func(i hierachy.IterItem[entry]) bool {
+		l := m.layoutOf(i, vb.Width)
+		vbItem := vb.Sub(tea.Rectangle{Height: l.height()})
 		if i.IsSelected {
 			vbItem = vbItem.Styled(styles.Style{}.Background(scuf.BgHiWhite))
 		}
-		vbItem = vbItem.PaddingLeft(2 * i.Depth) // TODO: also handle long strings, deep hierachies
+		if l.end-m.colOffset > vb.Width { // line continues past right edge
+			vbItem.PaddingLeft(vb.Width - 1).Styled(styles.Style{}.Foreground(scuf.FgHiBlack)).WriteLine("…")
+			vbItem = vbItem.Sub(tea.Rectangle{Width: vb.Width - 1})
+		}
+		// x is column of line start relative to vbItem, negative if scrolled
+		x := l.indent - m.colOffset
+		if x >= vbItem.Width {
+			return nextLine(l)
+		}
+		if x > 0 {
+			vbItem = vbItem.PaddingLeft(x)
+			x = 0
+		}
+		// changes inside expanded containers are shown on their children
+		if diff := i.Value.diff; diff != diffNone && !i.IsSelected && (diff != diffChanged || !i.HasChildren || i.IsCollapsed) {
+			vbItem = vbItem.Styled(styles.Style{}.Background(diffStyle(diff)))
+		}
+		start := x
 		if i.Value.isKey {
-			x := vbItem.Styled(fun.IF(
+			x = writeSpans(vbItem.Styled(fun.IF(
 				i.IsSelected,
 				styles.Style{}.Foreground(scuf.FgBlack),
 				styles.Style{}.Foreground(currentTheme.Key),
-			)).WriteLine(i.Value.key)
-			vbItem = vbItem.PaddingLeft(x)
-			vbItem = vbItem.WriteLineX(": ")
+			)), x, i.Value.key, 0, m.searchSpans(i.Value.path, true))
+			x = writeAt(vbItem, x, ": ")
+		}
+		if i.Value.decoded != "" {
+			x = writeAt(vbItem.Styled(styles.Style{}.Foreground(currentTheme.Preview)), x, i.Value.decoded+" ")
 		}
 		if i.HasChildren && i.IsCollapsed {
-			vbItem = vbItem.Styled(fun.IF(
+			vbBracket := vbItem.Styled(fun.IF(
 				i.IsSelected,
 				styles.Style{}.Foreground(scuf.FgBlack),
 				styles.Style{}.Foreground(currentTheme.Key),
 			))
-			if i.Value.kind == elemKindObject {
-				vbItem = vbItem.WriteLineX("{")
-				vbItem.Styled(styles.Style{}.Foreground(scuf.FgHiBlack)).WriteLine("...")
-				vbItem = vbItem.PaddingLeft(3).WriteLineX("}")
-			} else { // array
-				vbItem = vbItem.WriteLineX("[")
-				vbItem.Styled(styles.Style{}.Foreground(scuf.FgHiBlack)).WriteLine("...")
-				vbItem = vbItem.PaddingLeft(3).WriteLineX("]")
-			}
+			vbDim := vbItem.Styled(styles.Style{}.Foreground(scuf.FgHiBlack))
+			brackets := fun.IF(i.Value.kind == elemKindObject, "{}", "[]")
+			preview, count := collapsedText(i.Value, vb.Width-l.col)
+			x = writeAt(vbBracket, x, brackets[:1])
+			x = writeAt(vbItem.Styled(styles.Style{}.Foreground(currentTheme.Preview)), x, preview)
+			x = writeAt(vbDim, x, count)
+			x = writeAt(vbBracket, x, brackets[1:])
+			writeAt(vbDim, x, " "+formatSize(i.Value.size))
 		} else {
-			switch i.Value.kind {
-			case elemKindString:
-				vbItem = vbItem.Styled(styles.Style{}.Foreground(currentTheme.String))
-			case elemKindNumber:
-				vbItem = vbItem.Styled(styles.Style{}.Foreground(currentTheme.Number))
-			case elemKindBool:
-				vbItem = vbItem.Styled(styles.Style{}.Foreground(currentTheme.Bool))
-			case elemKindNull:
-				vbItem = vbItem.Styled(styles.Style{}.Foreground(currentTheme.Null))
+			style := styles.Style{}
+			switch {
+			case i.IsSelected:
+				style = style.Foreground(scuf.FgBlack)
+			case i.Value.kind == elemKindString:
+				style = style.Foreground(currentTheme.String)
+			case i.Value.kind == elemKindNumber:
+				style = style.Foreground(currentTheme.Number)
+			case i.Value.kind == elemKindBool:
+				style = style.Foreground(currentTheme.Bool)
+			case i.Value.kind == elemKindNull:
+				style = style.Foreground(currentTheme.Null)
 			}
-			if i.IsSelected {
-				vbItem = vbItem.Styled(styles.Style{}.Foreground(scuf.FgBlack))
+			if i.HasChildren { // opening bracket, closing one is on separate line
+				writeAt(vbItem.Styled(style), x, i.Value.value[:1])
+			} else {
+				spans, from := m.searchSpans(i.Value.path, false), 0
+				for j, chunk := range l.chunks {
+					writeSpans(vbItem.Row(l.skip+j).Styled(style), start+l.col-l.indent, chunk, from, spans)
+					from += len(chunk)
+				}
 			}
-			vbItem.WriteLine(i.Value.value)
 		}
 
-		vb = vb.PaddingTop(1)
-		height--
-		return height != 0
+		return nextLine(l)
 	})
 }
 
 func (m *model) View(vb tea.Viewbox) {
-	vbJSON, vbInput, vbError := vb.SplitY3(tea.Flex(1), tea.Fixed(1), tea.Fixed(1)) // TODO: show error only it exists
-	m.viewJSON(vbJSON)
-	m.digInput.View(vbInput)
-	vbError.WriteLine(m.queryError)
+	vbHeader, vbView, vbInput, vbError := vb.SplitY4(tea.Fixed(m.headerHeight()), tea.Flex(1), tea.Fixed(1), tea.Fixed(1)) // TODO: show error only it exists
+	row := 0
+	if len(m.stages) > 0 {
+		m.viewPipeline(vbHeader.Row(row))
+		row++
+	}
+	if len(m.zoom) > 0 {
+		m.viewBreadcrumbs(vbHeader.Row(row))
+	}
+	if m.split {
+		m.viewSplit(vbView)
+	} else {
+		m.viewPane(vbView)
+	}
+	if m.picker != nil {
+		m.viewPicker(vbView)
+	}
+	if m.digInput.Focused() {
+		m.digInput.View(vbInput)
+	} else {
+		m.viewStatusBar(vbInput)
+	}
+	switch {
+	case m.yank:
+		vbError.WriteLine("(y)value  (p)path  (k)key")
+	case m.searchInput.Focused():
+		m.viewSearch(vbError)
+	case m.cancelQuery != nil && time.Since(m.queryStart) >= spinnerInterval:
+		m.viewQueryProgress(vbError)
+	case m.queryError != "":
+		vbError.WriteLine(m.queryError)
+	case m.message != "":
+		vbError.WriteLine(m.message)
+	case m.count > 0:
+		vbError.WriteLine(strconv.Itoa(m.count))
+	case m.pending != "":
+		vbError.WriteLine(m.pending)
+	case m.search != nil:
+		m.viewSearch(vbError)
+	}
 }
 
-// func reFindAllStringIndex(re *regexp.Regexp, s string) [][2]int {
-// 	var res [][2]int
-// 	for _, v := range re.FindAllStringIndex(s, -1) {
-// 		res = append(res, [2]int(v[:2]))
-// 	}
-// 	return res
-// }
-
-// func regexCase(code string) (string, bool) {
-// 	switch {
-// 	case strings.HasSuffix(code, "/i"):
-// 		return code[:len(code)-2], true
-// 	case strings.HasSuffix(code, "/"):
-// 		return code[:len(code)-1], false
-// 	default:
-// 		return code, true
-// 	}
-// }
-
-// func reduce(fns []string) error {
-// 	script := filepath.Join(os.TempDir(), "fx.js")
-// 	if _, err := os.Stat(script); os.IsNotExist(err) {
-// 		if err := os.WriteFile(script, src, 0o644); err != nil {
-// 			return err
-// 		}
-// 	}
-
-// 	args := []string{script}
-// 	envVar := "NODE_OPTIONS=--max-old-space-size=16384"
-// 	bin, err := exec.LookPath("node")
-// 	if err != nil {
-// 		bin, err = exec.LookPath("deno")
-// 		if err != nil {
-// 			return errors.New("Node.js or Deno is required to run fx with reducers")
-// 		}
-
-// 		args = []string{"run", "-A", script}
-// 		envVar = "V8_FLAGS=--max-old-space-size=16384"
-// 	}
-
-// 	cmd := exec.Command(bin, append(args, fns...)...)
-// 	cmd.Env = append(os.Environ(), envVar)
-// 	cmd.Stdin = os.Stdin
-// 	cmd.Stdout = os.Stdout
-// 	cmd.Stderr = os.Stderr
-// 	return cmd.Run()
-// }
-
-// func flex(width int, a, b string) string {
-// 	return a + strings.Repeat(" ", max(1, width-len(a)-len(b))) + b
-// }
-
-// type model struct {
-// 	termWidth, termHeight int
-// 	head, top             *node
-// 	cursor                int // cursor position [0, termHeight)
-// 	fileName              string
-// 	digInput              textinput.Model
-// 	searchInput           textinput.Model
-// 	search                *search
-// 	showCursor            bool
-// 	wrap                  bool
-// 	yank                  bool
-// }
-
-// func (m *model) Init() tea.Cmd {
-// 	return nil
-// }
-
-// func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-// 	switch msg := msg.(type) {
-// 	case tea.WindowSizeMsg:
-// 		m.termWidth = msg.Width
-// 		m.termHeight = msg.Height
-// 		wrapAll(m.top, m.termWidth)
-// 		m.redoSearch()
-
-// 	case tea.MouseMsg:
-// 		switch {
-// 		case msg.Button&tea.MouseButtonWheelUp != 0:
-// 			m.up()
-
-// 		case msg.Button&tea.MouseButtonWheelDown != 0:
-// 			m.down()
-
-// 		case msg.Button&tea.MouseButtonLeft != 0:
-// 			m.digInput.Blur()
-// 			m.showCursor = true
-// 			if msg.Y >= m.viewHeight() {
-// 				break
-// 			}
-
-// 			if m.cursor == msg.Y {
-// 				to := m.cursorPointsTo()
-// 				if to == nil {
-// 					break
-// 				}
-
-// 				if to.isCollapsed() {
-// 					to.expand()
-// 				} else {
-// 					to.collapse()
-// 				}
-// 			} else {
-// 				to := m.at(msg.Y)
-// 				if to == nil {
-// 					break
-// 				}
-
-// 				m.cursor = msg.Y
-// 				if to.isCollapsed() {
-// 					to.expand()
-// 				}
-// 			}
-// 		}
-
-// 	case tea.KeyMsg:
-// 		if m.digInput.Focused() {
-// 			return m, m.handleDigKey(msg)
-// 		}
-// 		if m.searchInput.Focused() {
-// 			return m, m.handleSearchKey(msg)
-// 		}
-// 		if m.yank {
-// 			m.handleYankKey(msg)
-// 			return m, nil
-// 		}
-// 		return m, m.handleKey(msg)
-// 	}
-// 	return m, nil
-// }
-
-// func (m *model) handleDigKey(msg tea.KeyMsg) tea.Cmd {
-// 	var cmd tea.Cmd
-// 	switch {
-// 	case key.Matches(msg, arrowUp):
-// 		m.up()
-// 		m.digInput.SetValue(m.cursorPath())
-// 		m.digInput.CursorEnd()
-
-// 	case key.Matches(msg, arrowDown):
-// 		m.down()
-// 		m.digInput.SetValue(m.cursorPath())
-// 		m.digInput.CursorEnd()
-
-// 	case msg.Type == tea.KeyEscape:
-// 		m.digInput.Blur()
-
-// 	case msg.Type == tea.KeyTab:
-// 		m.digInput.SetValue(m.cursorPath())
-// 		m.digInput.CursorEnd()
-
-// 	case msg.Type == tea.KeyEnter:
-// 		m.digInput.Blur()
-// 		if digPath, ok := jsonpath.Split(m.digInput.Value()); ok {
-// 			if n := m.selectByPath(digPath); n != nil {
-// 				m.selectNode(n)
-// 			}
-// 		}
-
-// 	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+w"))):
-// 		if digPath, ok := jsonpath.Split(m.digInput.Value()); ok {
-// 			if len(digPath) > 0 {
-// 				digPath = digPath[:len(digPath)-1]
-// 			}
-// 			if n := m.selectByPath(digPath); n != nil {
-// 				m.selectNode(n)
-// 				m.digInput.SetValue(m.cursorPath())
-// 				m.digInput.CursorEnd()
-// 			}
-// 		}
-
-// 	case key.Matches(msg, textinput.DefaultKeyMap.WordBackward):
-// 		value := m.digInput.Value()
-// 		if path, ok := jsonpath.Split(value[0:m.digInput.Position()]); ok {
-// 			if len(path) > 0 {
-// 				path = path[:len(path)-1]
-// 				m.digInput.SetCursor(len(jsonpath.Join(path)))
-// 			} else {
-// 				m.digInput.CursorStart()
-// 			}
-// 		}
-
-// 	case key.Matches(msg, textinput.DefaultKeyMap.WordForward):
-// 		value := m.digInput.Value()
-// 		fullPath, ok1 := jsonpath.Split(value)
-// 		if path, ok2 := jsonpath.Split(value[0:m.digInput.Position()]); ok1 && ok2 {
-// 			if len(path) < len(fullPath) {
-// 				path = append(path, fullPath[len(path)])
-// 				m.digInput.SetCursor(len(jsonpath.Join(path)))
-// 			} else {
-// 				m.digInput.CursorEnd()
-// 			}
-// 		}
-
-// 	default:
-// 		if key.Matches(msg, key.NewBinding(key.WithKeys("."))) {
-// 			if m.digInput.Position() == len(m.digInput.Value()) {
-// 				m.digInput.SetValue(m.cursorPath())
-// 				m.digInput.CursorEnd()
-// 			}
-// 		}
-
-// 		m.digInput, cmd = m.digInput.Update(msg)
-// 		if n := m.dig(m.digInput.Value()); n != nil {
-// 			m.selectNode(n)
-// 		}
-// 	}
-// 	return cmd
-// }
-
-// func (m *model) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
-// 	var cmd tea.Cmd
-// 	switch {
-// 	case msg.Type == tea.KeyEscape:
-// 		m.searchInput.Blur()
-// 		m.searchInput.SetValue("")
-// 		m.doSearch("")
-// 		m.showCursor = true
-
-// 	case msg.Type == tea.KeyEnter:
-// 		m.searchInput.Blur()
-// 		m.doSearch(m.searchInput.Value())
-
-// 	default:
-// 		m.searchInput, cmd = m.searchInput.Update(msg)
-// 	}
-// 	return cmd
-// }
-
-// func (m *model) handleYankKey(msg tea.KeyMsg) {
-// 	switch {
-// 	case key.Matches(msg, yankPath):
-// 		_ = clipboard.WriteAll(m.cursorPath())
-// 	case key.Matches(msg, yankKey):
-// 		_ = clipboard.WriteAll(m.cursorKey())
-// 	case key.Matches(msg, yankValue):
-// 		_ = clipboard.WriteAll(m.cursorValue())
-// 	}
-// 	m.yank = false
-// }
-
-// func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
-// 	switch {
-// 	case key.Matches(msg, keyMap.Quit):
-// 		return tea.Quit
-
-// 	case key.Matches(msg, keyMap.Up):
-// 		m.up()
-
-// 	case key.Matches(msg, keyMap.Down):
-// 		m.down()
-
-// 	case key.Matches(msg, keyMap.PageUp):
-// 		m.cursor = 0
-// 		for i := 0; i < m.viewHeight(); i++ {
-// 			m.up()
-// 		}
-
-// 	case key.Matches(msg, keyMap.PageDown):
-// 		m.cursor = m.viewHeight() - 1
-// 		for i := 0; i < m.viewHeight(); i++ {
-// 			m.down()
-// 		}
-// 		m.scrollIntoView()
-
-// 	case key.Matches(msg, keyMap.HalfPageUp):
-// 		m.cursor = 0
-// 		for i := 0; i < m.viewHeight()/2; i++ {
-// 			m.up()
-// 		}
-
-// 	case key.Matches(msg, keyMap.HalfPageDown):
-// 		m.cursor = m.viewHeight() - 1
-// 		for i := 0; i < m.viewHeight()/2; i++ {
-// 			m.down()
-// 		}
-// 		m.scrollIntoView()
-
-// 	case key.Matches(msg, keyMap.GotoTop):
-// 		m.head = m.top
-// 		m.cursor = 0
-// 		m.showCursor = true
-
-// 	case key.Matches(msg, keyMap.GotoBottom):
-// 		m.head = m.findBottom()
-// 		m.cursor = 0
-// 		m.showCursor = true
-// 		m.scrollIntoView()
-
-// 	case key.Matches(msg, keyMap.NextSibling):
-// 		var nextSibling *node
-// 		if pointsTo := m.cursorPointsTo(); pointsTo.end != nil && pointsTo.end.next != nil {
-// 			nextSibling = pointsTo.end.next
-// 		} else {
-// 			nextSibling = pointsTo.next
-// 		}
-// 		if nextSibling != nil {
-// 			m.selectNode(nextSibling)
-// 		}
-
-// 	case key.Matches(msg, keyMap.PrevSibling):
-// 		pointsTo := m.cursorPointsTo()
-// 		var prevSibling *node
-// 		if pointsTo.parent() != nil && pointsTo.parent().end == pointsTo {
-// 			prevSibling = pointsTo.parent()
-// 		} else if pointsTo.prev != nil {
-// 			prevSibling = pointsTo.prev
-// 			parent := prevSibling.parent()
-// 			if parent != nil && parent.end == prevSibling {
-// 				prevSibling = parent
-// 			}
-// 		}
-// 		if prevSibling != nil {
-// 			m.selectNode(prevSibling)
-// 		}
-
-// 	case key.Matches(msg, keyMap.Collapse):
-// 		n := m.cursorPointsTo()
-// 		if n.hasChildren() && !n.isCollapsed() {
-// 			n.collapse()
-// 		} else if n.parent() != nil {
-// 			n = n.parent()
-// 		}
-// 		m.selectNode(n)
-
-// 	case key.Matches(msg, keyMap.Expand):
-// 		m.cursorPointsTo().expand()
-// 		m.showCursor = true
-
-// 	case key.Matches(msg, keyMap.CollapseRecursively):
-// 		if n := m.cursorPointsTo(); n.hasChildren() {
-// 			n.collapseRecursively()
-// 		}
-// 		m.showCursor = true
-
-// 	case key.Matches(msg, keyMap.ExpandRecursively):
-// 		if n := m.cursorPointsTo(); n.hasChildren() {
-// 			n.expandRecursively()
-// 		}
-// 		m.showCursor = true
-
-// 	case key.Matches(msg, keyMap.CollapseAll):
-// 		for n := m.top; n != nil; n = fun.Deref(n.end).next {
-// 			n.collapseRecursively()
-// 		}
-// 		m.cursor = 0
-// 		m.head = m.top
-// 		m.showCursor = true
-
-// 	case key.Matches(msg, keyMap.ExpandAll):
-// 		at := m.cursorPointsTo()
-// 		for n := m.top; n != nil; n = fun.Deref(n.end).next {
-// 			n.expandRecursively()
-// 		}
-// 		m.selectNode(at)
-
-// 	case key.Matches(msg, keyMap.ToggleWrap):
-// 		at := m.cursorPointsTo()
-// 		m.wrap = !m.wrap
-// 		if m.wrap {
-// 			wrapAll(m.top, m.termWidth)
-// 		} else {
-// 			dropWrapAll(m.top)
-// 		}
-// 		if at.chunk != nil && at.value == nil {
-// 			at = at.parent()
-// 		}
-// 		m.redoSearch()
-// 		m.selectNode(at)
-
-// 	case key.Matches(msg, keyMap.Yank):
-// 		m.yank = true
-
-// 	case key.Matches(msg, keyMap.Dig):
-// 		m.digInput.SetValue(m.cursorPath() + ".")
-// 		m.digInput.CursorEnd()
-// 		m.digInput.Width = m.termWidth - 1
-// 		m.digInput.Focus()
-
-// 	case key.Matches(msg, keyMap.Search):
-// 		m.searchInput.CursorEnd()
-// 		m.searchInput.Width = m.termWidth - 2 // -1 for the prompt, -1 for the cursor
-// 		m.searchInput.Focus()
-
-// 	case key.Matches(msg, keyMap.SearchNext):
-// 		m.selectSearchResult(m.search.cursor + 1)
-
-// 	case key.Matches(msg, keyMap.SearchPrev):
-// 		m.selectSearchResult(m.search.cursor - 1)
-// 	}
-// 	return nil
-// }
-
-// func (m *model) up() {
-// 	m.showCursor = true
-// 	m.cursor--
-// 	if m.cursor < 0 {
-// 		m.cursor = 0
-// 		if m.head.prev != nil {
-// 			m.head = m.head.prev
-// 		}
-// 	}
-// }
-
-// func (m *model) down() {
-// 	m.showCursor = true
-
-// 	if m.cursorPointsTo() == nil {
-// 		return
-// 	}
-
-// 	m.cursor++
-// 	if m.cursor >= m.viewHeight() {
-// 		m.cursor = m.viewHeight() - 1
-// 		if m.head.next != nil {
-// 			m.head = m.head.next
-// 		}
-// 	}
-// }
-
-// func (m *model) scrollIntoView() {
-// 	visibleLines := 0
-// 	for n := m.head; n != nil && visibleLines < m.viewHeight(); n = n.next {
-// 		visibleLines++
-// 	}
-
-// 	m.cursor = min(m.cursor, visibleLines-1)
-// 	for visibleLines < m.viewHeight() && m.head.prev != nil {
-// 		visibleLines++
-// 		m.cursor++
-// 		m.head = m.head.prev
-// 	}
-// }
-
-// func (m *model) View() string {
-// 	screen := ""
-// 	lineNumber := 0
-// 	for n := m.head; n != nil && lineNumber < m.viewHeight(); n = n.next {
-// 		screen += strings.Repeat("  ", n.depth)
-
-// 		// don't highlight the cursor while iterating search results
-// 		isSelected := m.cursor == lineNumber && m.showCursor
-
-// 		if n.key != nil {
-// 			screen += m.prettyKey(n, isSelected)
-// 			screen += colon
-// 			isSelected = false // don't highlight the key's value
-// 		}
-
-// 		screen += m.prettyPrint(n, isSelected)
-
-// 		if n.isCollapsed() {
-// 			if fun.Deref(n.value)[0] == '{' {
-// 				if n.collapsed.key != nil {
-// 					screen += scuf.String(*n.collapsed.key, currentTheme.Preview)
-// 					screen += colonPreview
-// 				}
-// 				screen += dot3
-// 				screen += closeCurlyBracket
-// 			} else if fun.Deref(n.value)[0] == '[' {
-// 				screen += dot3
-// 				screen += closeSquareBracket
-// 			}
-// 			if n.end != nil && n.end.comma {
-// 				screen += comma
-// 			}
-// 		}
-// 		if n.comma {
-// 			screen += comma
-// 		}
-
-// 		screen += "\n"
-// 		lineNumber++
-// 	}
-
-// 	screen += strings.Repeat(empty+"\n", max(0, m.viewHeight()-lineNumber))
-
-// 	if m.digInput.Focused() {
-// 		screen += m.digInput.View()
-// 	} else {
-// 		statusBar := flex(m.termWidth, m.cursorPath(), m.fileName)
-// 		screen += scuf.String(statusBar, currentTheme.StatusBar)
-// 	}
-
-// 	switch {
-// 	case m.yank:
-// 		screen += "\n"
-// 		screen += "(y)value  (p)path  (k)key"
-// 	case m.searchInput.Focused():
-// 		screen += "\n"
-// 		screen += m.searchInput.View()
-// 	case m.searchInput.Value() != "":
-// 		var msg string
-// 		switch {
-// 		case m.search.err != nil:
-// 			msg = m.search.err.Error()
-// 		case len(m.search.results) == 0:
-// 			msg = "not found"
-// 		default:
-// 			msg = fmt.Sprintf("found: [%v/%v]", m.search.cursor+1, len(m.search.results))
-// 		}
-
-// 		re, ci := regexCase(m.searchInput.Value())
-
-// 		screen += "\n"
-// 		screen += flex(m.termWidth, "/"+re+"/"+fun.IF(ci, "i", ""), msg)
-// 	}
-
-// 	return screen
-// }
-
-// func (m *model) prettyKey(node *node, selected bool) string {
-// 	b := node.key
-// 	style := fun.IF(selected, currentTheme.Cursor, currentTheme.Key)
-
-// 	indexes, ok := m.search.keys[node]
-// 	if !ok {
-// 		return scuf.String(*b, style)
-// 	}
-
-// 	out := ""
-// 	for i, p := range splitBytesByIndexes(b, indexes) {
-// 		switch {
-// 		case i%2 == 0:
-// 			out += scuf.String(string(p.b), style)
-// 		case p.index == m.search.cursor:
-// 			out += scuf.String(string(p.b), currentTheme.Cursor)
-// 		default:
-// 			out += scuf.String(string(p.b), currentTheme.Search)
-// 		}
-// 	}
-// 	return out
-// }
-
-// func (m *model) prettyPrint(node *node, selected bool) string {
-// 	b := fun.IF(node.chunk != nil, node.chunk, node.value)
-// 	if fun.Deref(b) == "" {
-// 		return ""
-// 	}
-
-// 	style := valueStyle(b, selected, node.chunk != nil)
-
-// 	if indexes, ok := m.search.values[node]; ok {
-// 		out := ""
-// 		for i, p := range splitBytesByIndexes(b, indexes) {
-// 			var toadd string
-// 			switch {
-// 			case i%2 == 0:
-// 				toadd = scuf.String(string(p.b), style)
-// 			case p.index == m.search.cursor:
-// 				toadd = scuf.String(string(p.b), currentTheme.Cursor)
-// 			default:
-// 				toadd = scuf.String(string(p.b), currentTheme.Search)
-// 			}
-// 			out += toadd
-// 		}
-// 		return out
-// 	}
-
-// 	return scuf.String(fun.Deref(b), style)
-// }
-
-// func (m *model) viewHeight() int {
-// 	if m.searchInput.Focused() || m.searchInput.Value() != "" {
-// 		return m.termHeight - 2
-// 	}
-// 	if m.yank {
-// 		return m.termHeight - 2
-// 	}
-// 	return m.termHeight - 1
-// }
-
-// func (m *model) cursorPointsTo() *node {
-// 	return m.at(m.cursor)
-// }
-
-// func (m *model) at(pos int) *node {
-// 	head := m.head
-// 	for i := 0; i < pos; i++ {
-// 		if head == nil {
-// 			break
-// 		}
-// 		head = head.next
-// 	}
-// 	return head
-// }
-
-// func (m *model) findBottom() *node {
-// 	n := m.head
-// 	for n.next != nil {
-// 		if n.end != nil {
-// 			n = n.end
-// 		} else {
-// 			n = n.next
-// 		}
-// 	}
-// 	return n
-// }
-
-// func (m *model) nodeInsideView(n *node) bool {
-// 	if n == nil {
-// 		return false
-// 	}
-// 	head := m.head
-// 	for i := 0; i < m.viewHeight(); i++ {
-// 		if head == nil {
-// 			break
-// 		}
-// 		if head == n {
-// 			return true
-// 		}
-// 		head = head.next
-// 	}
-// 	return false
-// }
-
-// func (m *model) selectNodeInView(n *node) {
-// 	head := m.head
-// 	for i := 0; i < m.viewHeight(); i++ {
-// 		if head == nil {
-// 			break
-// 		}
-
-// 		if head == n {
-// 			m.cursor = i
-// 			return
-// 		}
-
-// 		head = head.next
-// 	}
-// }
-
-// func (m *model) selectNode(n *node) {
-// 	m.showCursor = true
-// 	if m.nodeInsideView(n) {
-// 		m.selectNodeInView(n)
-// 		m.scrollIntoView()
-// 	} else {
-// 		m.cursor = 0
-// 		m.head = n
-// 		m.scrollIntoView()
-// 	}
-// 	parent := n.parent()
-// 	for parent != nil {
-// 		parent.expand()
-// 		parent = parent.parent()
-// 	}
-// }
-
-// func (m *model) cursorPath() string {
-// 	path := ""
-// 	for at := m.cursorPointsTo(); at != nil; at = at.parent() {
-// 		if at.prev == nil {
-// 			continue
-// 		}
-
-// 		if at.chunk != nil && at.value == nil {
-// 			at = at.parent()
-// 		}
-// 		if at.key != nil {
-// 			quoted := *at.key
-// 			unquoted, err := strconv.Unquote(quoted)
-// 			if err == nil && jsonpath.Identifier.MatchString(unquoted) {
-// 				path = "." + unquoted + path
-// 			} else {
-// 				path = "[" + quoted + "]" + path
-// 			}
-// 		} else if at.index >= 0 {
-// 			path = "[" + strconv.Itoa(at.index) + "]" + path
-// 		}
-// 	}
-// 	return path
-// }
-
-// func (m *model) cursorValue() string {
-// 	at := m.cursorPointsTo()
-// 	if at == nil {
-// 		return ""
-// 	}
-// 	var out strings.Builder
-// 	if at.chunk != nil && at.value == nil {
-// 		at = at.parent()
-// 	}
-// 	out.WriteString(fun.Deref(at.value))
-// 	if at.hasChildren() {
-// 		it := at.next
-// 		if at.isCollapsed() {
-// 			it = at.collapsed
-// 		}
-// 		for it != nil {
-// 			if it.key != nil {
-// 				out.WriteString(*it.key)
-// 				out.WriteString(": ")
-// 			}
-// 			if it.chunk != nil {
-// 				out.WriteString(*it.chunk)
-// 			} else {
-// 				out.WriteString(fun.Deref(it.value))
-// 			}
-// 			if it == at.end {
-// 				break
-// 			}
-// 			if it.comma {
-// 				out.WriteString(", ")
-// 			}
-// 			if it.isCollapsed() {
-// 				it = it.collapsed
-// 			} else {
-// 				it = it.next
-// 			}
-// 		}
-// 	}
-// 	return out.String()
-// }
-
-// func (m *model) cursorKey() string {
-// 	at := m.cursorPointsTo()
-// 	if at == nil {
-// 		return ""
-// 	}
-// 	if at.key != nil {
-// 		var v string
-// 		_ = json.Unmarshal([]byte(*at.key), &v)
-// 		return v
-// 	}
-// 	return strconv.Itoa(at.index)
-// }
-
-// func (m *model) selectByPath(path []any) *node {
-// 	n := m.currentTopNode()
-// 	for _, part := range path {
-// 		if n == nil {
-// 			return nil
-// 		}
-// 		switch part := part.(type) {
-// 		case string:
-// 			n = n.findChildByKey(part)
-// 		case int:
-// 			n = n.findChildByIndex(part)
-// 		}
-// 	}
-// 	return n
-// }
-
-// func (m *model) currentTopNode() *node {
-// 	at := m.cursorPointsTo()
-// 	if at == nil {
-// 		return nil
-// 	}
-// 	for at.parent() != nil {
-// 		at = at.parent()
-// 	}
-// 	return at
-// }
-
-// func (m *model) doSearch(s string) {
-// 	m.search = newSearch()
-
-// 	if s == "" {
-// 		return
-// 	}
-
-// 	code, ci := regexCase(s)
-// 	if ci {
-// 		code = "(?i)" + code
-// 	}
-
-// 	re, err := regexp.Compile(code)
-// 	if err != nil {
-// 		m.search.err = err
-// 		return
-// 	}
-
-// 	searchIndex := 0
-// 	for n := m.top; n != nil; n = fun.IF(n.isCollapsed(), n.collapsed, n.next) {
-// 		if n.key != nil {
-// 			if indexes := reFindAllStringIndex(re, *n.key); len(indexes) > 0 {
-// 				for i, pair := range indexes {
-// 					m.search.results = append(m.search.results, n)
-// 					m.search.keys[n] = append(m.search.keys[n], match{
-// 						start: pair[0],
-// 						end:   pair[1],
-// 						index: searchIndex + i,
-// 					})
-// 				}
-// 				searchIndex += len(indexes)
-// 			}
-// 		}
-
-// 		if indexes := reFindAllStringIndex(re, fun.Deref(n.value)); len(indexes) > 0 {
-// 			for range indexes {
-// 				m.search.results = append(m.search.results, n)
-// 			}
-// 			if n.chunk != nil {
-// 				// String can be split into chunks, so we need to map the indexes to the chunks.
-// 				chunkNodes := []*node{n}
-// 				chunks := [][]byte{[]byte(fun.Deref(n.chunk))}
-// 				for it := n.next; it != nil; it = it.next {
-// 					chunkNodes = append(chunkNodes, it)
-// 					chunks = append(chunks, []byte(fun.Deref(it.chunk)))
-// 					if it == n.chunkEnd {
-// 						break
-// 					}
-// 				}
-
-// 				chunkMatches := splitIndexesToChunks(chunks, indexes, searchIndex)
-// 				for i, matches := range chunkMatches {
-// 					m.search.values[chunkNodes[i]] = matches
-// 				}
-// 			} else {
-// 				for i, pair := range indexes {
-// 					m.search.values[n] = append(m.search.values[n], match{
-// 						start: pair[0],
-// 						end:   pair[1],
-// 						index: searchIndex + i,
-// 					})
-// 				}
-// 			}
-// 			searchIndex += len(indexes)
-// 		}
-// 	}
-
-// 	m.selectSearchResult(0)
-// }
-
-// func (m *model) selectSearchResult(i int) {
-// 	if len(m.search.results) == 0 {
-// 		return
-// 	}
-
-// 	if i < 0 {
-// 		i = len(m.search.results) - 1
-// 	}
-// 	if i >= len(m.search.results) {
-// 		i = 0
-// 	}
-
-// 	m.search.cursor = i
-// 	result := m.search.results[i]
-// 	m.selectNode(result)
-// 	m.showCursor = false
-// }
-
-// func (m *model) redoSearch() {
-// 	if m.searchInput.Value() == "" || len(m.search.results) == 0 {
-// 		return
-// 	}
-
-// 	cursor := m.search.cursor
-// 	m.doSearch(m.searchInput.Value())
-// 	m.selectSearchResult(cursor)
-// }
-
-// func (m *model) dig(v string) *node {
-// 	p, ok := jsonpath.Split(v)
-// 	if !ok {
-// 		return nil
-// 	}
-
-// 	at := m.selectByPath(p)
-// 	if at != nil {
-// 		return at
-// 	}
-
-// 	searchTerm, ok := p[len(p)-1].(string)
-// 	if !ok {
-// 		return nil
-// 	}
-
-// 	at = m.selectByPath(p[:len(p)-1])
-// 	if at == nil {
-// 		return nil
-// 	}
-
-// 	keys, nodes := at.children()
-
-// 	matches := fuzzy.Find(searchTerm, keys)
-// 	if len(matches) == 0 {
-// 		return nil
-// 	}
-
-// 	return nodes[matches[0].Index]
-// }
-
 func run() error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
 	var args []string
-	for _, arg := range os.Args[1:] {
-		switch arg {
+	var watchCommand string
+	watchInterval := defaultWatchInterval
+	queryTimeout := defaultQueryTimeout
+	queryLimit := defaultQueryLimit
+	expandStrings := false
+	for i := 1; i < len(os.Args); i++ {
+		switch arg := os.Args[i]; arg {
 		case "-h", "--help":
 			return ErrUsage
 		case "--themes":
 			themeTester()
 			return nil
+		case "--expand-strings":
+			expandStrings = true
+		case "--watch", "--interval":
+			i++
+			if i == len(os.Args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+
+			if arg == "--watch" {
+				watchCommand = os.Args[i]
+				break
+			}
+
+			interval, err := time.ParseDuration(os.Args[i])
+			if err != nil {
+				return fmt.Errorf("invalid interval: %w", err)
+			}
+			if interval <= 0 { // command would be rerun without pause
+				return fmt.Errorf("invalid interval: %s, must be positive", os.Args[i])
+			}
+			watchInterval = interval
+		case "--query-timeout", "--query-limit":
+			i++
+			if i == len(os.Args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+
+			if arg == "--query-timeout" {
+				timeout, err := time.ParseDuration(os.Args[i])
+				if err != nil {
+					return fmt.Errorf("invalid query timeout: %w", err)
+				}
+				queryTimeout = timeout
+				break
+			}
+
+			limit, err := strconv.Atoi(os.Args[i])
+			if err != nil || limit < 0 {
+				return fmt.Errorf("invalid query limit: %s", os.Args[i])
+			}
+			queryLimit = limit
 		default:
 			args = append(args, arg)
 		}
 	}
 
-	// var fileName string
+	fileName := "stdin"
 	var src io.Reader
 	switch stdinIsTty := isatty.IsTerminal(os.Stdin.Fd()); {
+	case watchCommand != "":
+		fileName = watchCommand
 	case stdinIsTty && len(args) == 0:
 		return ErrUsage
 	case stdinIsTty && len(args) == 1:
@@ -1190,22 +969,29 @@ func run() error {
 		if err != nil {
 			return err
 		}
-		// fileName = filepath.Base(filePath)
+		fileName = filepath.Base(filePath)
 		src = f
 	case !stdinIsTty && len(args) == 0:
 		src = os.Stdin
-	default:
-		// return reduce(args)
-	}
-
-	data, err := io.ReadAll(src)
-	if err != nil {
-		return err
+	default: // reducers are not supported
+		return ErrUsage
 	}
 
 	var original any
-	_ = json.Unmarshal(data, &original)
-	tree := fromJSON(original)
+	if watchCommand != "" {
+		v, err := runWatched(watchCommand)
+		if err != nil {
+			return err
+		}
+		original = v
+	} else {
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+
+		_ = json.Unmarshal(data, &original)
+	}
 
 	digInput := textinput.New()
 	digInput.Prompt = ""
@@ -1217,18 +1003,47 @@ func run() error {
 		Background(scuf.BgANSI(15)).
 		Foreground(scuf.FgANSI(0))
 
-	// searchInput := textinput.New()
-	// searchInput.Prompt = "/"
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
 
 	m := &model{
-		tree:       hierachy.New(tree),
-		original:   original,
-		digInput:   digInput,
-		result:     original,
-		queryError: "",
+		stage: stage{
+			pane: pane{
+				tree:       nil,
+				result:     original,
+				search:     nil,
+				filter:     false,
+				unfiltered: nil,
+				offset:     0,
+				colOffset:  0,
+				root:       nil,
+				zoom:       nil,
+				decoded:    map[string]bool{},
+			},
+			query:   ".",
+			stream:  nil,
+			origins: nil,
+		},
+		stages:       nil,
+		fileName:     fileName,
+		original:     original,
+		digInput:     digInput,
+		searchInput:  searchInput,
+		queryTimeout: queryTimeout,
+		queryLimit:   queryLimit,
+		queryError:   "",
+
+		watchCommand:  watchCommand,
+		watchInterval: watchInterval,
+
+		expandStrings: expandStrings,
+
+		wrap:  true,
+		marks: map[rune]jsonPath{},
 	}
+	m.tree = newHierachy(m.newTree(original))
 
-	_, err = tea.NewProgram(ctx, m).WithOutput(os.Stderr).Run()
+	_, err := tea.NewProgram(ctx, m).WithOutput(os.Stderr).WithMouseCellMotion().Run()
 	return err
 }
 
@@ -4,11 +4,12 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"reflect"
+	"sync"
 	"testing"
-	"time"
 
 	"github.com/rprtr258/tea"
-	"github.com/rprtr258/tea/components/headless/hierachy"
+	"github.com/rprtr258/tea/components/cursor"
 	"github.com/rprtr258/tea/components/textinput"
 	"github.com/rprtr258/tea/teatest"
 )
@@ -24,23 +25,92 @@ var _original = func() any {
 	return v
 }()
 
-func prepare(t *testing.T) *teatest.TestModel[*model] {
+// mustJSON parses JSON document of test case.
+func mustJSON(s string) any {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		panic(err.Error())
+	}
+	return v
+}
+
+func prepare(t *testing.T) *model {
+	t.Helper()
+
+	return prepareDocument(t, _original)
+}
+
+// prepareDocument returns model showing document v on 80x40 terminal.
+func prepareDocument(t *testing.T, v any) *model {
 	t.Helper()
 
 	digInput := textinput.New()
+	digInput.Prompt = ""
 	digInput.SetValue(".")
+	digInput.Cursor.SetMode(cursor.ModeStatic) // blink would make send wait for ticks
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+	searchInput.Cursor.SetMode(cursor.ModeStatic)
 
-	return teatest.NewTestModelFixture(
-		t,
-		&model{
-			tree:       hierachy.New(fromJSON(_original)),
-			original:   _original,
-			result:     _original,
-			queryError: "",
-			digInput:   digInput,
+	m := &model{
+		stage: stage{
+			pane: pane{
+				result:  v,
+				decoded: map[string]bool{},
+			},
+			query: ".",
 		},
-		teatest.WithInitialTermSize(80, 40),
-	)
+		fileName:     "example.json",
+		original:     v,
+		digInput:     digInput,
+		searchInput:  searchInput,
+		queryTimeout: defaultQueryTimeout,
+		queryLimit:   defaultQueryLimit,
+		wrap:         true,
+		marks:        map[rune]jsonPath{},
+	}
+	m.tree = newHierachy(m.newTree(v))
+	send(m, tea.MsgWindowSize{Width: 80, Height: 40})
+	return m
+}
+
+// send updates model with messages, running commands they yield until none
+// are left.
+func send(m *model, msgs ...tea.Msg) {
+	for len(msgs) > 0 {
+		var cmds []tea.Cmd
+		m.Update(msgs[0], func(c ...tea.Cmd) { cmds = append(cmds, c...) })
+		msgs = msgs[1:]
+		for _, cmd := range cmds {
+			if msg := cmd(); msg != nil {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+}
+
+// keys returns key presses typing s.
+func keys(s string) []tea.Msg {
+	res := make([]tea.Msg, 0, len(s))
+	for _, r := range s {
+		res = append(res, tea.MsgKey{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return res
+}
+
+// renderMu guards buffer screens are rendered into, which is shared by all
+// viewboxes.
+var renderMu sync.Mutex
+
+// render returns screen shown by model.
+func render(m *model) []byte {
+	renderMu.Lock()
+	defer renderMu.Unlock()
+
+	vb := tea.NewViewbox(m.termHeight, m.termWidth)
+	m.View(vb)
+	return bytes.Clone(vb.Render())
 }
 
 func Test(t *testing.T) {
@@ -65,26 +135,77 @@ func Test(t *testing.T) {
 		keys := keys
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
-			tm := prepare(t)
 
+			m := prepare(t)
 			for _, key := range keys {
-				tm.Send(key)
+				send(m, key)
 			}
+			teatest.RequireEqualOutput(t, render(m))
+		})
+	}
+}
 
-			var out []byte
-			teatest.WaitFor(t,
-				tm.Output(),
-				func(b []byte) bool {
-					out = b
-					return bytes.Contains(b, []byte("{"))
-				},
-				teatest.WithCheckInterval(time.Millisecond*100),
-				teatest.WithDuration(time.Second),
-			)
-			teatest.RequireEqualOutput(t, out)
-
-			tm.Send(tea.MsgKey{Type: tea.KeyRunes, Runes: []rune("q")})
-			tm.WaitFinished(t, teatest.WithFinalTimeout(time.Second))
+func TestScroll(t *testing.T) {
+	t.Parallel()
+
+	// 100 numbers with brackets take 102 lines, view is 38 lines high
+	numbers := make([]any, 100)
+	for i := range numbers {
+		numbers[i] = float64(i)
+	}
+	for name, tc := range map[string]struct {
+		keys           []tea.Msg
+		cursor, offset int
+	}{
+		"page down":      {[]tea.Msg{tea.MsgKey{Type: tea.KeyPgDown}}, 38, 38},
+		"half page down": {keys("d"), 19, 19},
+		"page up":        {[]tea.Msg{tea.MsgKey{Type: tea.KeyPgDown}, tea.MsgKey{Type: tea.KeyPgDown}, tea.MsgKey{Type: tea.KeyPgUp}}, 38, 26},
+		"half page up":   {keys("dddu"), 38, 38},
+		"count":          {keys("2d"), 38, 38},
+		"bottom":         {keys("G"), 101, 64},
+		"top":            {keys("Ggg"), 0, 0},
+		"line":           {keys("50G"), 49, 12},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := prepareDocument(t, numbers)
+			send(m, tc.keys...)
+			if got := cursorIndex(m.tree); got != tc.cursor {
+				t.Errorf("cursor = %d, want %d", got, tc.cursor)
+			}
+			if m.offset != tc.offset {
+				t.Errorf("offset = %d, want %d", m.offset, tc.offset)
+			}
 		})
 	}
 }
+
+func TestMouse(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": {"b": 1}, "c": [1, 2]}`))
+	click := tea.MsgMouse{Type: tea.MouseLeft, X: 5, Y: 1}
+	send(m, click)
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{"a"}) {
+		t.Errorf("cursor after click = %s, want .a", got)
+	}
+
+	send(m, click)
+	want := []string{".", ".a+", ".c", ".c[0]", ".c[1]", ".c/", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after click on selected node = %q, want %q", got, want)
+	}
+
+	numbers := make([]any, 100)
+	for i := range numbers {
+		numbers[i] = float64(i)
+	}
+	m = prepareDocument(t, numbers)
+	wheel := tea.MsgMouse{Type: tea.MouseWheelDown}
+	send(m, wheel, wheel, wheel)
+	if m.offset != 3 {
+		t.Errorf("offset after scrolling wheel = %d, want 3", m.offset)
+	}
+}
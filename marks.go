@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea"
+)
+
+const (
+	// maxCount limits count prefix of motions
+	maxCount = 99999
+	// maxJumps is number of positions kept in jump list
+	maxJumps = 100
+)
+
+// handlePendingKey handles key completing two-key command: sets or jumps to
+// mark named by key pressed after m or ', goes to top after gg, scrolls
+// columns after zh and zl or toggles wrap after zw.
+func (m *model) handlePendingKey(msg tea.MsgKey) {
+	pending, n := m.pending, max(1, m.count)
+	m.pending, m.count = "", 0
+	switch pending {
+	case "g":
+		if msg.String() == "g" {
+			m.pushJump()
+			m.gotoLine(n)
+		}
+		return
+	case "z":
+		switch msg.String() {
+		case "h", "left":
+			m.scrollColumns(-n * scrollStep)
+		case "l", "right":
+			m.scrollColumns(n * scrollStep)
+		case "w":
+			m.toggleWrap()
+		}
+		return
+	}
+
+	if len(msg.Runes) != 1 || msg.Runes[0] < 'a' || msg.Runes[0] > 'z' {
+		return
+	}
+
+	name := msg.Runes[0]
+	switch pending {
+	case "m":
+		m.marks[name] = m.tree.Selected().path
+	case "'":
+		if p, ok := m.marks[name]; ok {
+			m.pushJump()
+			selectPath(m.tree, p)
+		}
+	}
+}
+
+// gotoLine moves cursor to n-th visible node, counting from 1.
+func (m *model) gotoLine(n int) {
+	moveCursor(m.tree, fun.Clamp(n-1, 0, visibleCount(m.tree)-1)-cursorIndex(m.tree))
+}
+
+// pushJump remembers cursor position before large jump, positions jumped back
+// from are forgotten.
+func (m *model) pushJump() {
+	cursor := m.tree.Selected().path
+	m.jumps = m.jumps[:m.jump]
+	if n := len(m.jumps); n > 0 && m.jumps[n-1].Equal(cursor) {
+		return
+	}
+
+	m.jumps = append(m.jumps, cursor)
+	m.jumps = m.jumps[max(0, len(m.jumps)-maxJumps):]
+	m.jump = len(m.jumps)
+}
+
+// jumpBack moves cursor to previous position in jump list, current position
+// is remembered to return to it.
+func (m *model) jumpBack() {
+	if m.jump == len(m.jumps) {
+		m.pushJump()
+		m.jump = len(m.jumps) - 1
+	}
+	if m.jump <= 0 {
+		return
+	}
+
+	m.jump--
+	selectPath(m.tree, m.jumps[m.jump])
+}
+
+// jumpForward moves cursor to next position in jump list.
+func (m *model) jumpForward() {
+	if m.jump+1 >= len(m.jumps) {
+		return
+	}
+
+	m.jump++
+	selectPath(m.tree, m.jumps[m.jump])
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/rprtr258/tea"
+)
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"a": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12], "b": 1}`
+	for name, tc := range map[string]struct {
+		keys string
+		want int
+	}{
+		"down":           {"5j", 5},
+		"two digits":     {"12j", 12},
+		"up":             {"12j3k", 9},
+		"count is reset": {"3jj", 4},
+		"goto line":      {"7G", 6},
+		"goto top line":  {"G4gg", 3},
+		"clamped":        {"99999j", 16},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := prepareDocument(t, mustJSON(doc))
+			send(m, keys(tc.keys)...)
+			if got := cursorIndex(m.tree); got != tc.want {
+				t.Errorf("cursor after %q = %d, want %d", tc.keys, got, tc.want)
+			}
+			if m.count != 0 {
+				t.Errorf("count after %q = %d, want reset", tc.keys, m.count)
+			}
+		})
+	}
+}
+
+func TestMarks(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": {"b": 1}, "c": [1, 2]}`))
+	selectPath(m.tree, jsonPath{"c", 1})
+	send(m, keys("mxG'x")...)
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{"c", 1}) {
+		t.Errorf("cursor after jumping to mark = %s, want .c[1]", got)
+	}
+
+	// marks are kept by path, so they survive changes of document
+	m.reload(mustJSON(`{"c": [0, 1, 2], "d": 1}`))
+	send(m, keys("gg'x")...)
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{"c", 1}) {
+		t.Errorf("cursor after jumping to mark in changed document = %s, want .c[1]", got)
+	}
+
+	send(m, keys("gg'y")...)
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{}) {
+		t.Errorf("cursor after jumping to missing mark = %s, want .", got)
+	}
+}
+
+func TestJumpList(t *testing.T) {
+	t.Parallel()
+
+	back := tea.MsgKey{Type: tea.KeyCtrlO}
+	forward := tea.MsgKey{Type: tea.KeyTab}
+	m := prepareDocument(t, mustJSON(`{"a": {"b": 1}, "c": [1, 2]}`))
+	selectPath(m.tree, jsonPath{"a", "b"})
+	send(m, keys("G")...) // jump from .a.b
+	selectPath(m.tree, jsonPath{"c"})
+	send(m, keys("gg")...) // jump from .c
+
+	for i, tc := range []struct {
+		key  tea.Msg
+		want string
+	}{
+		{back, ".c"},
+		{back, ".a.b"},
+		{back, ".a.b"}, // start of list
+		{forward, ".c"},
+		{forward, "."},
+		{forward, "."}, // end of list
+	} {
+		send(m, tc.key)
+		selected := m.tree.Selected()
+		got := selected.path.String()
+		if selected.closing {
+			got += "/"
+		}
+		if got != tc.want {
+			t.Fatalf("cursor after %d-th jump = %s, want %s", i+1, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/scuf"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/textinput"
+	"github.com/rprtr258/tea/styles"
+)
+
+const (
+	// pickerIndexStep is number of nodes indexed between redraws, so that
+	// picker stays responsive while indexing large documents
+	pickerIndexStep = 20000
+	// maxPickerPreview is max number of bytes of value shown next to path
+	maxPickerPreview = 64
+)
+
+// msgPickerIndex asks picker to index next part of document.
+type msgPickerIndex struct {
+	picker *picker
+}
+
+// pickerItem is indexed path of document node with its value preview.
+type pickerItem struct {
+	path    jsonPath
+	label   string
+	preview string
+}
+
+// pickerMatch is item matched by fuzzy pattern, positions are byte offsets of
+// matched characters in item label.
+type pickerMatch struct {
+	item      int
+	score     int
+	positions []int
+}
+
+// picker is overlay listing all paths of document, filtered by fuzzy pattern.
+type picker struct {
+	input textinput.Model
+	items []pickerItem
+	// queue holds nodes not yet indexed, last one is indexed first
+	queue   []pickerItem
+	values  []any
+	matches []pickerMatch
+	// cursor is index of selected match, offset is index of first shown one
+	cursor, offset int
+}
+
+// openPicker shows picker over viewed document and starts indexing it.
+func (m *model) openPicker(yield func(...tea.Cmd)) {
+	v, _ := m.viewed(m.result)
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Focus()
+	m.picker = &picker{
+		input:   input,
+		items:   nil,
+		queue:   []pickerItem{{path: m.root, label: m.root.String(), preview: ""}},
+		values:  []any{v},
+		matches: nil,
+		cursor:  0,
+		offset:  0,
+	}
+	m.indexPicker(yield)
+}
+
+// indexPicker indexes next part of document and asks for one more step if
+// something is left.
+func (m *model) indexPicker(yield func(...tea.Cmd)) {
+	p := m.picker
+	from := len(p.items)
+	for n := 0; n < pickerIndexStep && len(p.queue) > 0; n++ {
+		last := len(p.queue) - 1
+		item, v := p.queue[last], p.values[last]
+		p.queue, p.values = p.queue[:last], p.values[:last]
+		if d, ok := v.(decodedString); ok {
+			v = d.value
+		}
+
+		item.preview = pickerPreview(v)
+		p.items = append(p.items, item)
+
+		// children are pushed in reverse to be indexed in document order
+		switch v := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for i := len(keys) - 1; i >= 0; i-- {
+				path := item.path.Key(keys[i])
+				p.queue = append(p.queue, pickerItem{path: path, label: path.String(), preview: ""})
+				p.values = append(p.values, v[keys[i]])
+			}
+		case []any:
+			for i := len(v) - 1; i >= 0; i-- {
+				path := item.path.Index(i)
+				p.queue = append(p.queue, pickerItem{path: path, label: path.String(), preview: ""})
+				p.values = append(p.values, v[i])
+			}
+		}
+	}
+
+	m.filterPicker(from)
+	if len(p.queue) > 0 {
+		yield(func() tea.Msg {
+			return msgPickerIndex{picker: p}
+		})
+	}
+}
+
+// pickerPreview returns short value shown next to path in picker.
+func pickerPreview(v any) string {
+	var s string
+	switch v := v.(type) {
+	case map[string]any:
+		s = fmt.Sprintf("{…} %d keys", len(v))
+	case []any:
+		s = fmt.Sprintf("[…] %d items", len(v))
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		s = string(b)
+	}
+
+	if len(s) > maxPickerPreview {
+		s = strings.ToValidUTF8(s[:maxPickerPreview], "") + "…"
+	}
+	return s
+}
+
+// fuzzyMatch finds characters of pattern in order in s, preferring
+// consecutive ones and ones at start of path parts. Pattern is case
+// insensitive unless it has upper case letters.
+func fuzzyMatch(pattern, s string) (score int, positions []int, ok bool) {
+	caseSensitive := strings.IndexFunc(pattern, unicode.IsUpper) != -1
+	fold := func(r rune) rune {
+		return fun.IF(caseSensitive, r, unicode.ToLower(r))
+	}
+
+	rs := []rune(s)
+	offsets := make([]int, len(rs))
+	for i, off := 0, 0; i < len(rs); i++ {
+		offsets[i] = off
+		off += len(string(rs[i]))
+	}
+
+	// find end of leftmost match, then go back from it to find shortest one
+	ps := []rune(pattern)
+	end, j := -1, 0
+	for i := 0; i < len(rs) && j < len(ps); i++ {
+		if fold(rs[i]) == fold(ps[j]) {
+			j++
+			end = i
+		}
+	}
+	if j < len(ps) {
+		return 0, nil, false
+	}
+
+	idx := make([]int, len(ps))
+	j = len(ps) - 1
+	for i := end; i >= 0 && j >= 0; i-- {
+		if fold(rs[i]) == fold(ps[j]) {
+			idx[j] = i
+			j--
+		}
+	}
+
+	for k, i := range idx {
+		score++
+		if k > 0 && idx[k-1] == i-1 {
+			score += 4
+		}
+		if i == 0 || strings.ContainsRune(`.["_ `, rs[i-1]) {
+			score += 3
+		}
+		positions = append(positions, offsets[i])
+	}
+	score -= (idx[len(idx)-1] - idx[0]) / 4 // prefer compact matches
+	return score, positions, true
+}
+
+// filterPicker matches items indexed starting from from-th one against
+// pattern and adds them to matches, keeping selected match.
+func (m *model) filterPicker(from int) {
+	p := m.picker
+	selected := -1
+	if p.cursor < len(p.matches) {
+		selected = p.matches[p.cursor].item
+	}
+
+	pattern := p.input.Value()
+	if from == 0 {
+		p.matches = p.matches[:0]
+	}
+	for i := from; i < len(p.items); i++ {
+		item := p.items[i]
+		if pattern == "" {
+			p.matches = append(p.matches, pickerMatch{item: i, score: 0, positions: nil})
+			continue
+		}
+
+		if score, positions, ok := fuzzyMatch(pattern, item.label); ok {
+			p.matches = append(p.matches, pickerMatch{item: i, score: score, positions: positions})
+		}
+	}
+	sort.SliceStable(p.matches, func(i, j int) bool {
+		return p.matches[i].score > p.matches[j].score
+	})
+
+	p.cursor = 0
+	for i, match := range p.matches {
+		if match.item == selected {
+			p.cursor = i
+			break
+		}
+	}
+}
+
+func (m *model) handlePickerKey(msg tea.MsgKey, yield func(...tea.Cmd)) {
+	p := m.picker
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.picker = nil
+	case msg.Type == tea.KeyEnter:
+		m.picker = nil
+		if p.cursor < len(p.matches) {
+			m.pushJump()
+			selectPath(m.tree, p.items[p.matches[p.cursor].item].path)
+		}
+	case msg.Type == tea.KeyUp || msg.String() == "ctrl+p":
+		p.cursor = max(0, p.cursor-1)
+	case msg.Type == tea.KeyDown || msg.String() == "ctrl+n":
+		p.cursor = min(max(0, len(p.matches)-1), p.cursor+1)
+	default:
+		value := p.input.Value()
+		p.input.Update(msg, yield)
+		if p.input.Value() != value {
+			m.filterPicker(0)
+			p.cursor = 0
+		}
+	}
+}
+
+// viewPicker shows picker input and matches over JSON view.
+func (m *model) viewPicker(vb tea.Viewbox) {
+	p := m.picker
+	vb.Fill(' ')
+	vbInput, vbList := vb.SplitY2(tea.Fixed(1), tea.Flex(1))
+	counter := fmt.Sprintf("%d/%d", len(p.matches), len(p.items))
+	if len(p.queue) > 0 {
+		counter = "indexing… " + counter
+	}
+	vbCounter := vbInput.PaddingLeft(max(0, vbInput.Width-len([]rune(counter))))
+	vbCounter.Styled(styles.Style{}.Foreground(currentTheme.Preview)).WriteLine(counter)
+	p.input.View(vbInput.Sub(tea.Rectangle{Width: max(1, vbInput.Width-len([]rune(counter))-1)}))
+	if vbList.Height <= 0 {
+		return
+	}
+
+	// keep cursor in view
+	p.offset = fun.Clamp(p.offset, p.cursor-vbList.Height+1, p.cursor)
+	for row := 0; row < vbList.Height && p.offset+row < len(p.matches); row++ {
+		match := p.matches[p.offset+row]
+		item := p.items[match.item]
+		vbRow := vbList.Row(row)
+		selected := p.offset+row == p.cursor
+		if selected {
+			vbRow = vbRow.Styled(styles.Style{}.Background(scuf.BgHiWhite))
+			vbRow.Fill(' ')
+		}
+
+		spans := fun.Map[span](func(pos int, _ int) span {
+			_, size := utf8.DecodeRuneInString(item.label[pos:])
+			return span{start: pos, end: pos + size, mod: currentTheme.Search}
+		}, match.positions...)
+		x := writeSpans(vbRow.Styled(fun.IF(
+			selected,
+			styles.Style{}.Foreground(scuf.FgBlack),
+			styles.Style{}.Foreground(currentTheme.Key),
+		)), 0, item.label, 0, spans)
+		writeAt(vbRow.Styled(styles.Style{}.Foreground(currentTheme.Preview)), x, "  "+item.preview)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/cursor"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		pattern, s string
+		score      int
+		positions  []int
+	}{
+		"whole":            {"abc", "abc", 3 + 8 + 3, []int{0, 1, 2}},
+		"part start":       {"name", ".name", 4 + 12 + 3, []int{1, 2, 3, 4}},
+		"scattered":        {"name", ".n_a_m_e", 4 + 12 - 1, []int{1, 3, 5, 7}},
+		"shortest match":   {"nm", ".users[0].name", 2 + 3, []int{10, 12}},
+		"case insensitive": {"ab", "AB", 2 + 4 + 3, []int{0, 1}},
+		"byte offsets":     {"é", ".café", 1, []int{4}},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			score, positions, ok := fuzzyMatch(tc.pattern, tc.s)
+			if !ok || score != tc.score || !reflect.DeepEqual(positions, tc.positions) {
+				t.Errorf("fuzzyMatch(%q, %q) = %d, %v, %v, want %d, %v", tc.pattern, tc.s, score, positions, ok, tc.score, tc.positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchFails(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		pattern, s string
+	}{
+		"missing":        {"xyz", "abc"},
+		"order":          {"ba", "ab"},
+		"case sensitive": {"AB", "ab"},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, _, ok := fuzzyMatch(tc.pattern, tc.s); ok {
+				t.Errorf("fuzzyMatch(%q, %q) matched", tc.pattern, tc.s)
+			}
+		})
+	}
+}
+
+func TestPicker(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"users": [{"name": "a"}, {"name": "b"}], "total": 2}`))
+	send(m, tea.MsgKey{Type: tea.KeyCtrlP})
+	m.picker.input.Cursor.SetMode(cursor.ModeStatic)
+	send(m, keys("us1nam")...)
+	if got := len(m.picker.matches); got != 1 {
+		t.Fatalf("matches = %d, want 1", got)
+	}
+
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+	if m.picker != nil {
+		t.Errorf("picker is left open")
+	}
+	if got, want := m.tree.Selected().path, (jsonPath{"users", 1, "name"}); !got.Equal(want) {
+		t.Errorf("cursor = %s, want %s", got, want)
+	}
+}
+
+func TestPickerIndex(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, make([]any, pickerIndexStep+10))
+	var cmds []tea.Cmd
+	m.Update(tea.MsgKey{Type: tea.KeyCtrlP}, func(c ...tea.Cmd) { cmds = append(cmds, c...) })
+	if got := len(m.picker.items); got != pickerIndexStep {
+		t.Errorf("indexed %d items on open, want %d", got, pickerIndexStep)
+	}
+	if len(cmds) != 1 {
+		t.Fatalf("open yielded %d commands, want 1", len(cmds))
+	}
+	msg := cmds[0]()
+
+	// reopened picker indexes root and all elements as send runs yielded
+	// commands
+	p := m.picker
+	send(m, tea.MsgKey{Type: tea.KeyEsc}, tea.MsgKey{Type: tea.KeyCtrlP})
+	if got, want := len(m.picker.items), pickerIndexStep+11; got != want {
+		t.Errorf("indexed %d items, want %d", got, want)
+	}
+
+	// indexing of closed picker is dropped
+	send(m, msg)
+	if got := len(p.items); got != pickerIndexStep {
+		t.Errorf("closed picker indexed %d items, want %d", got, pickerIndexStep)
+	}
+}
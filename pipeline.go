@@ -0,0 +1,112 @@
+package main
+
+import (
+	"maps"
+
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/styles"
+)
+
+// pipelineSeparator separates queries of pipeline stages in header.
+const pipelineSeparator = " → "
+
+// stage is applied query of pipeline with view of its result. Each stage
+// runs over result of previous one, the first one over original document.
+type stage struct {
+	pane
+	// query is jq program whose result is shown, dig input differs from it
+	// while typing
+	query string
+	// stream is iterator of query results, if there is more than one of them
+	stream *resultStream
+	// origins are paths in input of query results, if query selects them by
+	// path, nil if they are not computed for current query and input yet
+	origins *origins
+}
+
+// stageInput returns document i-th stage is run over.
+func (m *model) stageInput(i int) any {
+	if i == 0 {
+		return m.original
+	}
+	return m.stages[i-1].result
+}
+
+// pipeline returns queries of all stages, shown one is the last.
+func (m *model) pipeline() []string {
+	res := make([]string, 0, len(m.stages)+1)
+	for _, s := range m.stages {
+		res = append(res, s.query)
+	}
+	return append(res, m.query)
+}
+
+// pushStage starts new stage over shown result, previous one is kept with its
+// cursor and folds. Fetching its results is paused until it is shown again,
+// so that next stage runs over ones which are shown.
+func (m *model) pushStage() {
+	if m.stream != nil {
+		if m.stream.fetching { // page is kept until stage is shown again
+			m.cancelQuery = nil
+		}
+		m.stream.pause()
+	}
+	m.stopQuery()
+	m.stages = append(m.stages, m.stage)
+	m.decoded = maps.Clone(m.decoded)
+	m.stream = nil
+	m.query = "."
+	m.origins = nil
+}
+
+// popStage shows i-th stage again, dropping ones after it.
+func (m *model) popStage(i int) {
+	if i < 0 || i >= len(m.stages) {
+		return
+	}
+
+	m.stopQuery()
+	m.stopStream()
+	for _, s := range m.stages[i+1:] {
+		if s.stream != nil {
+			s.stream.cancel()
+		}
+	}
+	m.pushJump()
+	m.stage = m.stages[i]
+	m.stages = m.stages[:i]
+	m.queryError = ""
+	m.resumeStream()
+	// document could be changed by watch since stage was left
+	m.reload(m.result)
+}
+
+// discardEmptyStage drops stage just started by dig input, if no query was
+// applied in it, as it shows the same document as previous one. Error of
+// failed query is kept.
+func (m *model) discardEmptyStage() {
+	if m.query == "." && len(m.stages) > 0 {
+		queryError := m.queryError
+		m.popStage(len(m.stages) - 1)
+		m.queryError = queryError
+	}
+}
+
+func (m *model) viewPipeline(vb tea.Viewbox) {
+	for i, query := range m.pipeline() {
+		if i > 0 {
+			vb = vb.Styled(styles.Style{}.Foreground(currentTheme.Preview)).WriteLineX(pipelineSeparator)
+		}
+		vb = vb.Styled(styles.Style{}.Foreground(currentTheme.Key)).WriteLineX(query)
+	}
+}
+
+// pipelineStage returns stage whose query is shown at column x of header.
+func (m *model) pipelineStage(x int) (int, bool) {
+	return crumbAt(m.pipeline(), pipelineSeparator, x)
+}
+
+// showsOriginal is whether shown result is original document as is.
+func (m *model) showsOriginal() bool {
+	return len(m.stages) == 0 && m.query == "."
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rprtr258/tea"
+)
+
+func TestPushPopStage(t *testing.T) {
+	t.Parallel()
+
+	original := mustJSON(`{"a": {"b": [1, 2], "c": 3}}`)
+	m := &model{original: original}
+	m.query = "."
+	m.result = original
+	m.tree = newHierachy(m.newTree(original))
+
+	// . → .a → .b, cursor is kept in left stage
+	m.pushStage()
+	m.showResult(".a", mustJSON(`{"b": [1, 2], "c": 3}`), nil)
+	selectPath(m.tree, jsonPath{"c"})
+	m.pushStage()
+	m.showResult(".b", mustJSON(`[1, 2]`), nil)
+
+	if got, want := m.pipeline(), []string{".", ".a", ".b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("pipeline() = %q, want %q", got, want)
+	}
+	if got := m.stageInput(2); !reflect.DeepEqual(got, m.stages[1].result) {
+		t.Errorf("stageInput(2) = %v, want result of .a", got)
+	}
+
+	m.popStage(1)
+	if got, want := m.pipeline(), []string{".", ".a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("pipeline() after popStage(1) = %q, want %q", got, want)
+	}
+	if got, want := m.result, mustJSON(`{"b": [1, 2], "c": 3}`); !reflect.DeepEqual(got, want) {
+		t.Errorf("result after popStage(1) = %v, want %v", got, want)
+	}
+	if got, want := m.tree.Selected().path.Value(), (jsonPath{"c"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("cursor after popStage(1) = %v, want %v", got, want)
+	}
+
+	m.popStage(0)
+	if got, want := m.pipeline(), []string{"."}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("pipeline() after popStage(0) = %q, want %q", got, want)
+	}
+	if !m.showsOriginal() {
+		t.Errorf("showsOriginal() = false after popping all stages")
+	}
+
+	m.popStage(0) // no stages left
+	if got, want := m.pipeline(), []string{"."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pipeline() after popStage of missing stage = %q, want %q", got, want)
+	}
+}
+
+func TestDiscardEmptyStage(t *testing.T) {
+	t.Parallel()
+
+	original := mustJSON(`{"a": 1}`)
+	m := &model{original: original}
+	m.query = "."
+	m.result = original
+	m.tree = newHierachy(m.newTree(original))
+
+	m.pushStage()
+	m.queryError = "compile: unexpected EOF"
+	m.discardEmptyStage()
+	if len(m.stages) != 0 || m.queryError == "" {
+		t.Errorf("discardEmptyStage() left %d stages, error %q, want no stages and error kept", len(m.stages), m.queryError)
+	}
+}
+
+func TestStageDecoded(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": "eyJiIjoxfQ==", "c": 1}`))
+	send(m, keys("jx")...)
+	// paths of decoded strings point into input of stage, not its result
+	dig(m, "{a}")
+	if want := []string{".", ".a", "./"}; !reflect.DeepEqual(visiblePaths(m.tree), want) {
+		t.Errorf("stage = %q, want %q", visiblePaths(m.tree), want)
+	}
+
+	// decoding and encoding string back in stage does not change previous one
+	send(m, keys("jxx")...)
+	send(m, keys("U")...)
+	if want := []string{".", ".a", ".a.b", ".a/", ".c", "./"}; !reflect.DeepEqual(visiblePaths(m.tree), want) {
+		t.Errorf("previous stage = %q, want %q", visiblePaths(m.tree), want)
+	}
+}
+
+func TestStagePaused(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	dig(m, "range(250)")
+	cmds := update(m, keys("G")[0])
+
+	// page fetched while next stage is shown is kept until stage is shown again
+	send(m, keys(".")...)
+	if m.cancelQuery != nil {
+		t.Errorf("left stage is shown as running")
+	}
+	send(m, cmds[0]())
+	if got := m.stages[len(m.stages)-1].stream.String(); got != "100+ results" {
+		t.Errorf("left stream = %q, want 100+ results", got)
+	}
+
+	// cursor is still at end of list, so rest of results is fetched too
+	send(m, tea.MsgKey{Type: tea.KeyEsc})
+	want := make([]any, 250)
+	for i := range want {
+		want[i] = i
+	}
+	if !reflect.DeepEqual(m.result, want) {
+		t.Errorf("result = %v, want range(250)", m.result)
+	}
+}
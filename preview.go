@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+// maxPreviewWidth limits length of children preview kept in entry.
+const maxPreviewWidth = 256
+
+// summarize fills size, count and preview of node from its children.
+func summarize(node *hierachy.Node[entry]) {
+	e := &node.Value
+	if e.kind != elemKindObject && e.kind != elemKindArray {
+		e.size = len(e.value)
+		return
+	}
+
+	e.count = len(node.Children)
+	e.size = len("{}") + max(0, e.count-1) // brackets and commas
+	width := 0
+	for _, child := range node.Children {
+		c := child.Value
+		e.size += c.size
+		if c.isKey {
+			e.size += len(c.key) + len(":")
+		}
+
+		if width > maxPreviewWidth {
+			continue
+		}
+
+		part := c.value
+		if c.count > 0 {
+			part = fun.IF(c.kind == elemKindObject, "{…}", "[…]")
+		}
+		if c.isKey {
+			part = c.key + ": " + part
+		}
+		e.preview = append(e.preview, part)
+		width += runewidth.StringWidth(part)
+	}
+}
+
+// collapsedText returns preview of first children of collapsed node which
+// fits into width columns along with other parts of line, and number of
+// children, which is empty if all of them are shown.
+func collapsedText(e entry, width int) (preview, count string) {
+	noun := fun.IF(e.kind == elemKindObject, "key", "item")
+	if e.count != 1 {
+		noun += "s"
+	}
+	count = fmt.Sprintf("… %d %s", e.count, noun)
+	width -= runewidth.StringWidth("{" + count + "} " + formatSize(e.size))
+
+	var sb strings.Builder
+	shown := 0
+	for _, part := range e.preview {
+		part += ", "
+		if w := runewidth.StringWidth(part); w <= width {
+			sb.WriteString(part)
+			width -= w
+			shown++
+			continue
+		}
+		break
+	}
+
+	if shown == e.count {
+		return strings.TrimSuffix(sb.String(), ", "), ""
+	}
+	return sb.String(), count
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestCollapsedText(t *testing.T) {
+	t.Parallel()
+
+	object := entry{kind: elemKindObject, size: 23, count: 3, preview: []string{`"a": 1`, `"b": 2`, `"c": 3`}}
+	for name, tc := range map[string]struct {
+		e       entry
+		width   int
+		preview string
+		count   string
+	}{
+		"all shown": {
+			e:       object,
+			width:   80,
+			preview: `"a": 1, "b": 2, "c": 3`,
+			count:   "",
+		},
+		"some shown": {
+			e:       object,
+			width:   30,
+			preview: `"a": 1, `,
+			count:   "… 3 keys",
+		},
+		"none shown": {
+			e:       object,
+			width:   10,
+			preview: "",
+			count:   "… 3 keys",
+		},
+		"single item": {
+			e:       entry{kind: elemKindArray, size: 3, count: 1, preview: []string{"1"}},
+			width:   80,
+			preview: "1",
+			count:   "",
+		},
+		"preview cut": {
+			e:       entry{kind: elemKindArray, size: 11, count: 5, preview: []string{"1", "2"}},
+			width:   80,
+			preview: "1, 2, ",
+			count:   "… 5 items",
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			preview, count := collapsedText(tc.e, tc.width)
+			if preview != tc.preview || count != tc.count {
+				t.Errorf("collapsedText() = %q, %q, want %q, %q", preview, count, tc.preview, tc.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/styles"
+)
+
+const (
+	// queryDebounce is time dig input must not change for to run it
+	queryDebounce       = 200 * time.Millisecond
+	defaultQueryTimeout = 10 * time.Second
+	defaultQueryLimit   = 100_000
+	// spinnerInterval is time between spinner frames, spinner is shown only
+	// for queries running longer than that
+	spinnerInterval = 100 * time.Millisecond
+	// cancelGrace is time after query has finished during which esc, which
+	// was meant to cancel it, does not quit
+	cancelGrace = 500 * time.Millisecond
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// msgQueryDebounce is sent when dig input has not changed for debounce
+// interval.
+type msgQueryDebounce struct {
+	id int
+}
+
+// msgQuerySpinner asks to redraw spinner of running query.
+type msgQuerySpinner struct {
+	id int
+}
+
+// msgQueryResult is first page of results of jq program from dig input.
+type msgQueryResult struct {
+	id      int
+	program string
+	stream  *resultStream
+	page    resultPage
+}
+
+// previewQuery schedules run of changed dig input, previous run is cancelled.
+func (m *model) previewQuery(yield func(...tea.Cmd)) {
+	m.stopQuery()
+	id := m.queryID
+	yield(tea.Tick(queryDebounce, func(time.Time) tea.Msg {
+		return msgQueryDebounce{id: id}
+	}))
+}
+
+// stopQuery cancels running and scheduled runs of dig input.
+func (m *model) stopQuery() {
+	m.queryID++
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+		m.cancelQuery = nil
+	}
+}
+
+// startQuery runs jq program over input of shown stage in background,
+// fetching first page of results. Returns whether program has compiled.
+func (m *model) startQuery(program string, yield func(...tea.Cmd)) bool {
+	s, err := startStream(program, m.stageInput(len(m.stages)))
+	if err != nil {
+		m.queryError = err.Error()
+		return false
+	}
+
+	m.cancelQuery = s.cancel
+	m.queryStart = time.Now()
+	id, timeout, limit := m.queryID, m.queryTimeout, m.queryLimit
+	yield(func() tea.Msg {
+		return msgQueryResult{
+			id:      id,
+			program: program,
+			stream:  s,
+			page:    s.fetch(2, timeout, limit),
+		}
+	}, m.cmdSpinner())
+	return true
+}
+
+func (m *model) cmdSpinner() tea.Cmd {
+	id := m.queryID
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return msgQuerySpinner{id: id}
+	})
+}
+
+// viewQueryProgress shows spinner and time running query takes.
+func (m *model) viewQueryProgress(vb tea.Viewbox) {
+	elapsed := time.Since(m.queryStart)
+	frame := spinnerFrames[int(elapsed/spinnerInterval)%len(spinnerFrames)]
+	vb = vb.Styled(styles.Style{}.Foreground(currentTheme.Key)).WriteLineX(frame + " ")
+	vb.WriteLine(fmt.Sprintf("running query %.1fs, press esc to cancel", elapsed.Seconds()))
+}
+
+// handleQueryResult shows result of latest run of dig input, previous result
+// is kept if program is incomplete or invalid.
+func (m *model) handleQueryResult(msg msgQueryResult) {
+	s := msg.stream
+	if msg.id != m.queryID {
+		s.cancel()
+		return
+	}
+
+	m.cancelQuery = nil
+	m.queryEnd = time.Now()
+	if msg.page.err != nil {
+		m.queryError = msg.page.err.Error()
+		if !m.digInput.Focused() { // applied program has failed
+			m.discardEmptyStage()
+		}
+		return
+	}
+
+	m.queryError = ""
+	s.add(msg.page)
+	switch {
+	case msg.program == m.query:
+		s.cancel()
+	case s.done && len(msg.page.values) == 1:
+		m.showResult(msg.program, msg.page.values[0], nil)
+	default:
+		m.showResult(msg.program, append([]any{}, msg.page.values...), s)
+	}
+}
+
+// showResult shows result of jq program as new document, stream is set if
+// it has more than one result.
+func (m *model) showResult(program string, result any, stream *resultStream) {
+	m.stopStream()
+	m.stream = stream
+	m.query = program
+	m.stopOrigins()
+	m.result = result
+	m.decoded = nil // paths of decoded strings point into previous result
+	m.pushJump()
+	m.resetZoom()
+	m.redoSearch()
+	m.tree = newHierachy(m.newTree(m.result))
+	m.offset = 0
+}
+
+// runPaths returns paths of values selected by jq program, which must
+// output paths, e.g. path(.items[]), in order program outputs them. Paths not
+// made of keys and indices are nil.
+func runPaths(ctx context.Context, program string, v any, timeout time.Duration, limit int) ([]jsonPath, error) {
+	code, err := compileQuery(program)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var paths []jsonPath
+	iter := code.RunWithContext(ctx, v)
+	for limit == 0 || len(paths) < limit {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		if err, ok := v.(error); ok {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("query timed out after %v", timeout)
+			}
+			return nil, fmt.Errorf("execute: %w", err)
+		}
+
+		p, _ := toJSONPath(v)
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// toJSONPath converts path returned by gojq, ok is false for paths which are
+// not made of keys and indices, e.g. of array slices.
+func toJSONPath(v any) (jsonPath, bool) {
+	parts, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+
+	p := make(jsonPath, 0, len(parts))
+	for _, part := range parts {
+		switch part := part.(type) {
+		case string:
+			p = p.Key(part)
+		case int:
+			p = p.Index(part)
+		case float64: // index computed by program
+			p = p.Index(int(part))
+		default:
+			return nil, false
+		}
+	}
+	return p, true
+}
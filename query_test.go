@@ -0,0 +1,189 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rprtr258/tea"
+)
+
+// update updates model with msg, returning yielded commands without running
+// them.
+func update(m *model, msg tea.Msg) []tea.Cmd {
+	var cmds []tea.Cmd
+	m.Update(msg, func(c ...tea.Cmd) { cmds = append(cmds, c...) })
+	return cmds
+}
+
+func TestPreviewQuery(t *testing.T) {
+	t.Parallel()
+
+	doc := mustJSON(`{"a": 1, "ab": 2}`)
+	m := prepareDocument(t, doc)
+	send(m, keys(".")...)
+	first := update(m, keys("a")[0])
+	last := update(m, keys("b")[0])
+	if len(first) != 1 || len(last) != 1 {
+		t.Fatalf("typing yielded %d and %d commands, want 1", len(first), len(last))
+	}
+
+	start := time.Now()
+	stale := first[0]()
+	if elapsed := time.Since(start); elapsed < queryDebounce {
+		t.Errorf("preview is scheduled in %v, want %v", elapsed, queryDebounce)
+	}
+
+	// input has changed since first key, so it is not run
+	send(m, stale)
+	if m.cancelQuery != nil || !reflect.DeepEqual(m.result, doc) {
+		t.Errorf("stale preview is run, result = %v", m.result)
+	}
+
+	send(m, last[0]())
+	if m.query != ".ab" || m.result != 2.0 {
+		t.Errorf("preview shows %v of %q, want 2 of .ab", m.result, m.query)
+	}
+	if !m.digInput.Focused() {
+		t.Errorf("dig input is closed by preview")
+	}
+
+	// previewed result is kept without running query again
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+	if m.cancelQuery != nil || m.query != ".ab" || len(m.stages) != 1 {
+		t.Errorf("applied preview: running %v, query %q, %d stages", m.cancelQuery != nil, m.query, len(m.stages))
+	}
+}
+
+// endless is jq program running until it is cancelled.
+const endless = `last(repeat(1))`
+
+func TestQueryTimeout(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	m.queryTimeout = 50 * time.Millisecond
+	send(m, keys(".")...)
+	m.digInput.SetValue(endless)
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+	if want := "execute: query timed out after 50ms"; m.queryError != want {
+		t.Errorf("error = %q, want %q", m.queryError, want)
+	}
+	if m.cancelQuery != nil || len(m.stages) != 0 {
+		t.Errorf("failed query: running %v, %d stages", m.cancelQuery != nil, len(m.stages))
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	m.queryLimit = 3
+	send(m, keys(".")...)
+	m.digInput.SetValue("range(10)")
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+	if want := []any{0, 1, 2}; !reflect.DeepEqual(m.result, want) {
+		t.Errorf("result = %v, want %v", m.result, want)
+	}
+	if got, want := m.stream.String(), "3+ results, limit reached"; got != want {
+		t.Errorf("stream = %q, want %q", got, want)
+	}
+}
+
+func TestQueryCancel(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	send(m, keys(".")...)
+	m.digInput.SetValue(endless)
+	cmds := update(m, tea.MsgKey{Type: tea.KeyEnter})
+	if m.cancelQuery == nil {
+		t.Fatalf("query is not running")
+	}
+
+	send(m, tea.MsgKey{Type: tea.KeyEsc})
+	send(m, cmds[0]()) // fetch ends as query is cancelled
+	if m.queryError != "query cancelled" || m.cancelQuery != nil || len(m.stages) != 0 {
+		t.Errorf("cancelled query: error %q, running %v, %d stages", m.queryError, m.cancelQuery != nil, len(m.stages))
+	}
+	if !reflect.DeepEqual(m.result, _original) {
+		t.Errorf("result of cancelled query is shown")
+	}
+}
+
+func TestQueryCancelPreview(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	send(m, keys(".")...)
+	m.digInput.SetValue(endless)
+	m.digInput.CursorEnd()
+	debounce := update(m, keys(" ")[0])
+	cmds := update(m, debounce[0]())
+	if m.cancelQuery == nil {
+		t.Fatalf("preview is not running")
+	}
+
+	// esc in dig input cancels preview, keeping shown result
+	send(m, tea.MsgKey{Type: tea.KeyEsc})
+	send(m, cmds[0]())
+	if m.queryError != "query cancelled" || m.cancelQuery != nil || m.digInput.Focused() {
+		t.Errorf("cancelled preview: error %q, running %v, input open %v", m.queryError, m.cancelQuery != nil, m.digInput.Focused())
+	}
+	if !reflect.DeepEqual(m.result, _original) || len(m.stages) != 0 {
+		t.Errorf("result of cancelled preview is shown")
+	}
+}
+
+func TestEscAfterQuery(t *testing.T) {
+	t.Parallel()
+
+	quits := func(cmds []tea.Cmd) bool {
+		for _, cmd := range cmds {
+			if _, ok := cmd().(tea.MsgQuit); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	m := prepare(t)
+	send(m, keys(".")...)
+	m.digInput.SetValue(".title")
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+	if quits(update(m, tea.MsgKey{Type: tea.KeyEsc})) {
+		t.Errorf("esc right after query has finished quits")
+	}
+
+	time.Sleep(cancelGrace)
+	if !quits(update(m, tea.MsgKey{Type: tea.KeyEsc})) {
+		t.Errorf("esc does not quit")
+	}
+}
+
+func TestToJSONPath(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		v    any
+		want jsonPath
+		ok   bool
+	}{
+		"root":           {[]any{}, jsonPath{}, true},
+		"keys":           {[]any{"a", "b c"}, jsonPath{"a", "b c"}, true},
+		"indices":        {[]any{"items", 0, 2}, jsonPath{"items", 0, 2}, true},
+		"computed index": {[]any{"items", 1.0}, jsonPath{"items", 1}, true},
+		"slice":          {[]any{"items", map[string]any{"start": 1, "end": 2}}, nil, false},
+		"not a path":     {"items", nil, false},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := toJSONPath(tc.v)
+			if ok != tc.ok || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("toJSONPath(%v) = %v, %v, want %v, %v", tc.v, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
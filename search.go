@@ -0,0 +1,652 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/scuf"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+	"github.com/rprtr258/tea/styles"
+)
+
+// searchMatch is single occurrence of search pattern in key or value of node,
+// start and end are byte offsets in shown key or value.
+type searchMatch struct {
+	path       jsonPath
+	inKey      bool
+	start, end int
+}
+
+type search struct {
+	// input is searched pattern, paths is whether it is jq program selecting
+	// paths
+	input string
+	paths bool
+	// selection is paths selected by jq program of paths search
+	selection *pathSelection
+	err       error
+	results   []searchMatch
+	// cursor is index of selected result
+	cursor int
+	// byPath maps path of node to indices of its results
+	byPath map[string][]int
+}
+
+// span is part of shown text written with modifier.
+type span struct {
+	start, end int
+	mod        scuf.Modifier
+}
+
+// regexCase splits input into pattern and whether it is case insensitive,
+// pattern may be terminated with / for case sensitive or /i for insensitive
+// search, which is the default.
+func regexCase(code string) (string, bool) {
+	switch {
+	case strings.HasSuffix(code, "/i"):
+		return code[:len(code)-2], true
+	case strings.HasSuffix(code, "/"):
+		return code[:len(code)-1], false
+	default:
+		return code, true
+	}
+}
+
+// searchQualifiers are prefixes of search terms, which make search input be
+// parsed as a list of terms instead of single regex.
+var searchQualifiers = []string{"key:", "value:", "type:", "in:"}
+
+// searchQuery is parsed search input. Input without qualifiers is single regex
+// matched against keys and values. Otherwise it is list of space separated
+// terms, all of which must match node:
+//
+//	re          key or value matches regex
+//	key:re      key matches regex
+//	value:re    value matches regex
+//	value:>1000 number compares to given one, also >=, <, <= and =
+//	type:null   node has one of given types
+//	in:.items   node is inside subtree at given path
+type searchQuery struct {
+	keysOrValues []*regexp.Regexp
+	keys         []*regexp.Regexp
+	values       []*regexp.Regexp
+	compares     []func(float64) bool
+	types        []elemKind
+	in           []jsonPath
+}
+
+// parseComparison parses number comparison like >1000, ok is false if s is
+// not one.
+func parseComparison(s string) (cmp func(float64) bool, ok bool, err error) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+
+		y, err := strconv.ParseFloat(s[len(op):], 64)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid number in comparison: %s", s)
+		}
+
+		switch op {
+		case ">=":
+			return func(x float64) bool { return x >= y }, true, nil
+		case "<=":
+			return func(x float64) bool { return x <= y }, true, nil
+		case ">":
+			return func(x float64) bool { return x > y }, true, nil
+		case "<":
+			return func(x float64) bool { return x < y }, true, nil
+		default:
+			return func(x float64) bool { return x == y }, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func parseSearch(input string) (searchQuery, error) {
+	code, ci := regexCase(input)
+	compile := func(code string) (*regexp.Regexp, error) {
+		return regexp.Compile(fun.IF(ci, "(?i)", "") + code)
+	}
+
+	var q searchQuery
+	terms := strings.Fields(code)
+	qualified := fun.Any(func(term string) bool {
+		return fun.Any(func(prefix string) bool {
+			return strings.HasPrefix(term, prefix)
+		}, searchQualifiers...)
+	}, terms...)
+	if !qualified {
+		re, err := compile(code)
+		if err != nil {
+			return searchQuery{}, err
+		}
+
+		q.keysOrValues = []*regexp.Regexp{re}
+		return q, nil
+	}
+
+	for _, term := range terms {
+		switch {
+		case strings.HasPrefix(term, "key:"):
+			re, err := compile(strings.TrimPrefix(term, "key:"))
+			if err != nil {
+				return searchQuery{}, err
+			}
+
+			q.keys = append(q.keys, re)
+		case strings.HasPrefix(term, "value:"):
+			code := strings.TrimPrefix(term, "value:")
+			cmp, ok, err := parseComparison(code)
+			if err != nil {
+				return searchQuery{}, err
+			}
+			if ok {
+				q.compares = append(q.compares, cmp)
+				continue
+			}
+
+			re, err := compile(code)
+			if err != nil {
+				return searchQuery{}, err
+			}
+
+			q.values = append(q.values, re)
+		case strings.HasPrefix(term, "type:"):
+			name := strings.TrimPrefix(term, "type:")
+			if name == "boolean" { // as named by jq
+				name = elemKindBool.String()
+			}
+
+			n := len(q.types)
+			for kind := elemKindObject; kind <= elemKindBool; kind++ {
+				if kind.String() == name {
+					q.types = append(q.types, kind)
+				}
+			}
+			if len(q.types) == n {
+				return searchQuery{}, fmt.Errorf("unknown type: %s", name)
+			}
+		case strings.HasPrefix(term, "in:"):
+			p, err := parsePath(strings.TrimPrefix(term, "in:"))
+			if err != nil {
+				return searchQuery{}, err
+			}
+
+			q.in = append(q.in, p)
+		default:
+			re, err := compile(term)
+			if err != nil {
+				return searchQuery{}, err
+			}
+
+			q.keysOrValues = append(q.keysOrValues, re)
+		}
+	}
+	return q, nil
+}
+
+// unquote returns text of shown key or string value without quotes and
+// escapes, along with offsets of its bytes in shown text, including one past
+// the last byte. Text which is not quoted is returned as is.
+func unquote(s string) (string, []int) {
+	if raw, offsets, ok := unquoteOffsets(s); ok {
+		return raw, offsets
+	}
+
+	offsets := make([]int, len(s)+1)
+	for i := range offsets {
+		offsets[i] = i
+	}
+	return s, offsets
+}
+
+func unquoteOffsets(s string) (string, []int, bool) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", nil, false
+	}
+
+	var raw []byte
+	var offsets []int
+	for i := 1; i < len(s)-1; {
+		r, multibyte, tail, err := strconv.UnquoteChar(s[i:len(s)-1], '"')
+		if err != nil {
+			return "", nil, false
+		}
+
+		n := len(raw)
+		if multibyte {
+			raw = utf8.AppendRune(raw, r)
+		} else {
+			raw = append(raw, byte(r))
+		}
+		for range raw[n:] {
+			offsets = append(offsets, i)
+		}
+		i = len(s) - 1 - len(tail)
+	}
+	return string(raw), append(offsets, len(s)-1), true
+}
+
+// match returns occurrences of query regexes in key and value of e, ok is
+// false if some term does not match e. Qualified regexes are matched against
+// unquoted key and string value, so that ^ and $ anchor to text itself.
+func (q searchQuery) match(e entry) (matches []searchMatch, ok bool) {
+	isLeaf := e.kind != elemKindObject && e.kind != elemKindArray
+	find := func(re *regexp.Regexp, s string, inKey, raw bool) bool {
+		offsets := []int(nil)
+		if raw {
+			s, offsets = unquote(s)
+		}
+		if !re.MatchString(s) {
+			return false
+		}
+
+		for _, loc := range re.FindAllStringIndex(s, -1) {
+			if loc[0] == loc[1] { // empty match can't be highlighted
+				continue
+			}
+			if offsets != nil {
+				loc = []int{offsets[loc[0]], offsets[loc[1]]}
+			}
+
+			matches = append(matches, searchMatch{
+				path:  e.path,
+				inKey: inKey,
+				start: loc[0],
+				end:   loc[1],
+			})
+		}
+		return true
+	}
+
+	for _, re := range q.keys {
+		if !e.isKey || !find(re, e.key, true, true) {
+			return nil, false
+		}
+	}
+	for _, re := range q.values {
+		if !isLeaf || !find(re, e.value, false, e.kind == elemKindString) {
+			return nil, false
+		}
+	}
+	for _, re := range q.keysOrValues {
+		inKey := e.isKey && find(re, e.key, true, false)
+		inValue := isLeaf && find(re, e.value, false, false)
+		if !inKey && !inValue {
+			return nil, false
+		}
+	}
+	if len(q.compares) > 0 {
+		x, err := strconv.ParseFloat(e.value, 64)
+		if e.kind != elemKindNumber || err != nil {
+			return nil, false
+		}
+
+		for _, cmp := range q.compares {
+			if !cmp(x) {
+				return nil, false
+			}
+		}
+	}
+	if len(q.types) > 0 && !fun.Contains(e.kind, q.types...) {
+		return nil, false
+	}
+	for _, p := range q.in {
+		if !e.path.HasPrefix(p) {
+			return nil, false
+		}
+	}
+
+	if len(matches) == 0 { // matched by qualifiers only
+		matches = append(matches, wholeMatch(e))
+	}
+	return matches, true
+}
+
+// wholeMatch highlights whole value of leaf node, key of container or its
+// bracket if it has no key.
+func wholeMatch(e entry) searchMatch {
+	if e.isKey && (e.kind == elemKindObject || e.kind == elemKindArray) {
+		return searchMatch{
+			path:  e.path,
+			inKey: true,
+			start: 0,
+			end:   len(e.key),
+		}
+	}
+
+	return searchMatch{
+		path:  e.path,
+		inKey: false,
+		start: 0,
+		end:   len(e.value),
+	}
+}
+
+// newSearch finds all nodes of viewed document matching input, including
+// ones hidden in collapsed nodes. Paths searched by jq program are taken from
+// selection, nodes are not matched until its program finishes.
+func (m *model) newSearch(input string, selection *pathSelection) *search {
+	res := &search{
+		input:     input,
+		paths:     selection != nil,
+		selection: selection,
+		err:       nil,
+		results:   nil,
+		cursor:    0,
+		byPath:    map[string][]int{},
+	}
+
+	var match func(entry) ([]searchMatch, bool)
+	switch {
+	case selection != nil && !m.sourceFocused && !m.showsOriginal():
+		res.err = errors.New("paths are searched in original, open split view with s")
+		return res
+	case selection != nil:
+		if selection.err != nil {
+			res.err = selection.err
+			return res
+		}
+
+		match = func(e entry) ([]searchMatch, bool) {
+			if _, ok := selection.paths[e.path.String()]; !ok {
+				return nil, false
+			}
+			return []searchMatch{wholeMatch(e)}, true
+		}
+	default:
+		q, err := parseSearch(input)
+		if err != nil {
+			res.err = err
+			return res
+		}
+
+		match = q.match
+	}
+
+	var walk func(node hierachy.Node[entry])
+	walk = func(node hierachy.Node[entry]) {
+		if matches, ok := match(node.Value); ok {
+			for _, match := range matches {
+				res.byPath[match.path.String()] = append(res.byPath[match.path.String()], len(res.results))
+				res.results = append(res.results, match)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(m.documentTree(m.result))
+	return res
+}
+
+// doSearch runs search for pattern from search input and selects its first
+// result. Paths search selects it once its program finishes.
+func (m *model) doSearch(yield func(...tea.Cmd)) {
+	// paths are highlighted in original document, which is shown in its own
+	// pane in split view
+	if m.searchPaths && m.split && !m.sourceFocused {
+		m.swapPanes()
+	}
+	m.stopSearch()
+	switch {
+	case m.searchInput.Value() == "":
+	case m.searchPaths:
+		m.search = m.newSearch(m.searchInput.Value(), &pathSelection{})
+		if m.search.err == nil {
+			m.runPathSearch(yield)
+		}
+		return
+	default:
+		m.search = m.newSearch(m.searchInput.Value(), nil)
+	}
+	if m.filter {
+		m.setFilter(true)
+	}
+	m.selectSearchResult(0)
+}
+
+// redoSearch updates results before tree is rebuilt after document or its
+// view has changed, keeping cursor position. Paths selected by jq program
+// are not searched again.
+func (m *model) redoSearch() {
+	if m.search == nil {
+		return
+	}
+
+	cursor := m.search.cursor
+	m.search = m.newSearch(m.search.input, m.search.selection)
+	m.search.cursor = fun.Clamp(cursor, 0, max(0, len(m.search.results)-1))
+}
+
+// selectSearchResult selects i-th search result, wrapping around, and expands
+// its collapsed ancestors.
+func (m *model) selectSearchResult(i int) {
+	if m.search == nil || len(m.search.results) == 0 {
+		return
+	}
+
+	n := len(m.search.results)
+	m.search.cursor = (i%n + n) % n
+	m.pushJump()
+	selectPath(m.tree, m.search.results[m.search.cursor].path)
+}
+
+// pathSelection is set of paths selected by jq program of paths search. It is
+// shared by copies of search kept by pipeline stages, since program is run in
+// background.
+type pathSelection struct {
+	// paths are formatted paths selected by last finished run of program
+	paths map[string]struct{}
+	err   error
+	// run identifies latest run of program, cancel stops it, nil if it has
+	// finished
+	run    int
+	cancel context.CancelFunc
+}
+
+// msgSearchPaths is result of run of paths search program.
+type msgSearchPaths struct {
+	selection *pathSelection
+	run       int
+	paths     []jsonPath
+	err       error
+}
+
+// runPathSearch runs program of paths search of pane in focus over its
+// document in background, previous run is cancelled and its paths are
+// highlighted until new ones are found.
+func (m *model) runPathSearch(yield func(...tea.Cmd)) {
+	selection := m.search.selection
+	if selection.cancel != nil {
+		selection.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	selection.run++
+	selection.cancel = cancel
+	run, program, v, timeout, limit := selection.run, m.search.input, m.result, m.queryTimeout, m.queryLimit
+	yield(func() tea.Msg {
+		paths, err := runPaths(ctx, "path("+program+")", v, timeout, limit)
+		return msgSearchPaths{
+			selection: selection,
+			run:       run,
+			paths:     paths,
+			err:       err,
+		}
+	})
+}
+
+// rerunPathSearch runs program of paths search of pane in focus again after
+// its document has changed.
+func (m *model) rerunPathSearch(yield func(...tea.Cmd)) {
+	if m.search != nil && m.search.selection != nil && (m.sourceFocused || m.showsOriginal()) {
+		m.runPathSearch(yield)
+	}
+}
+
+// handleSearchPaths highlights paths found by paths search program in panes
+// showing its search, first result is selected after first run.
+func (m *model) handleSearchPaths(msg msgSearchPaths) {
+	selection := msg.selection
+	if msg.run != selection.run || selection.cancel == nil { // stale or cancelled
+		return
+	}
+
+	first := selection.paths == nil
+	selection.cancel()
+	selection.cancel = nil
+	selection.paths, selection.err = map[string]struct{}{}, msg.err
+	for _, p := range msg.paths {
+		if p != nil {
+			selection.paths[p.String()] = struct{}{}
+		}
+	}
+
+	update := func() {
+		if m.search == nil || m.search.selection != selection {
+			return
+		}
+
+		m.redoSearch()
+		if m.filter {
+			m.setFilter(true)
+		}
+		if first {
+			m.selectSearchResult(0)
+		}
+	}
+	update()
+	m.inOtherPane(update)
+}
+
+// searchRunning is whether program of paths search of pane in focus is
+// running.
+func (m *model) searchRunning() bool {
+	return m.search != nil && m.search.selection != nil && m.search.selection.cancel != nil
+}
+
+// stopSearch drops search of pane in focus, cancelling its program.
+func (m *model) stopSearch() {
+	if m.searchRunning() {
+		selection := m.search.selection
+		selection.cancel()
+		selection.cancel = nil
+		if selection.paths == nil { // copies kept by stages have no results
+			selection.err = errors.New("search cancelled")
+		}
+	}
+	m.search = nil
+}
+
+// openSearch focuses search input, paths is whether it is jq program
+// selecting paths to highlight instead of regex.
+func (m *model) openSearch(paths bool) {
+	if paths != m.searchPaths {
+		m.searchInput.SetValue("")
+	}
+	m.searchPaths = paths
+	m.searchInput.Prompt = fun.IF(paths, "&", "/")
+	m.searchInput.CursorEnd()
+	m.searchInput.Focus()
+}
+
+func (m *model) handleSearchKey(msg tea.MsgKey, yield func(...tea.Cmd)) {
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.searchInput.Blur()
+		m.searchInput.SetValue("")
+		m.stopSearch()
+		if m.filter {
+			m.setFilter(false)
+		}
+	case msg.Type == tea.KeyEnter:
+		m.searchInput.Blur()
+		m.doSearch(yield)
+	default:
+		m.searchInput.Update(msg, yield)
+	}
+}
+
+// searchSpans returns highlighted parts of key or value of node with given
+// path, current result is highlighted as cursor.
+func (m *model) searchSpans(p jsonPath, inKey bool) []span {
+	if m.search == nil {
+		return nil
+	}
+
+	var spans []span
+	for _, i := range m.search.byPath[p.String()] {
+		match := m.search.results[i]
+		if match.inKey != inKey {
+			continue
+		}
+
+		spans = append(spans, span{
+			start: match.start,
+			end:   match.end,
+			mod:   fun.IF(i == m.search.cursor, currentTheme.Cursor, currentTheme.Search),
+		})
+	}
+	return spans
+}
+
+// writeSpans writes s at column x like writeAt, parts of s covered by spans
+// are written with their modifiers. Spans are relative to text starting from
+// from-th byte, s is part of it.
+func writeSpans(vb tea.Viewbox, x int, s string, from int, spans []span) int {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	pos := 0
+	for _, sp := range spans {
+		start := fun.Clamp(sp.start-from, pos, len(s))
+		end := fun.Clamp(sp.end-from, start, len(s))
+		x = writeAt(vb, x, s[pos:start])
+		x = writeAt(vb.Styled(styles.Style{}.Foreground(sp.mod)), x, s[start:end])
+		pos = end
+	}
+	return writeAt(vb, x, s[pos:])
+}
+
+// viewSearch shows search input or pattern and results of last search.
+func (m *model) viewSearch(vb tea.Viewbox) {
+	if m.searchInput.Focused() {
+		m.searchInput.View(vb)
+		return
+	}
+
+	if m.search.paths {
+		vb.WriteLine("&" + m.search.input)
+	} else {
+		re, ci := regexCase(m.search.input)
+		vb.WriteLine("/" + re + "/" + fun.IF(ci, "i", ""))
+	}
+
+	var msg string
+	switch {
+	case m.searchRunning():
+		msg = "searching, press esc to cancel"
+	case m.search.err != nil:
+		msg = m.search.err.Error()
+	case len(m.search.results) == 0:
+		msg = "not found"
+	default:
+		msg = fmt.Sprintf("found: [%v/%v]", m.search.cursor+1, len(m.search.results))
+	}
+	if m.filter {
+		msg = "filter, " + msg
+	}
+	vb.PaddingLeft(max(0, vb.Width-len(msg))).WriteLine(msg)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rprtr258/tea"
+)
+
+func TestParseSearchInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"(",
+		"key:(",
+		"value:>x",
+		"type:date",
+		"in:items",
+	} {
+		input := input
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := parseSearch(input); err == nil {
+				t.Errorf("parseSearch(%q) succeeded, want error", input)
+			}
+		})
+	}
+}
+
+func TestSearchMatch(t *testing.T) {
+	t.Parallel()
+
+	str := func(p jsonPath, key, value string) entry {
+		return entry{kind: elemKindString, isKey: true, key: strconv.Quote(key), value: strconv.Quote(value), path: p}
+	}
+	name := str(jsonPath{"users", 0, "name"}, "name", "Error: disk")
+	status := str(jsonPath{"status"}, "status", "error")
+	size := entry{kind: elemKindNumber, isKey: true, key: `"size"`, value: "2048", path: jsonPath{"size"}}
+	items := entry{kind: elemKindArray, isKey: true, key: `"items"`, value: "[", path: jsonPath{"items"}}
+	for name, tc := range map[string]struct {
+		input string
+		e     entry
+		// want is highlighted parts of key and value, nil if e does not match
+		want []string
+	}{
+		"regex in key and value":  {`st|rr`, status, []string{`key:st`, `value:rr`}},
+		"case sensitive":          {`Error/`, status, nil},
+		"case insensitive":        {`ERROR`, name, []string{`value:Error`}},
+		"quotes are searched":     {`"error"`, status, []string{`value:"error"`}},
+		"anchored key":            {`key:^name$`, name, []string{`key:name`}},
+		"anchored value":          {`value:^error$`, status, []string{`value:error`}},
+		"anchored value mismatch": {`value:^error$`, name, nil},
+		"key and value":           {`key:stat value:err`, status, []string{`key:stat`, `value:err`}},
+		"value of container":      {`value:i`, items, nil},
+		"comparison":              {`value:>1000`, size, []string{`value:2048`}},
+		"comparison fails":        {`value:<1000`, size, nil},
+		"comparison not number":   {`value:>1`, status, nil},
+		"type":                    {`type:array`, items, []string{`key:"items"`}},
+		"type mismatch":           {`type:null type:number`, items, nil},
+		"in subtree":              {`in:.users name`, name, []string{`key:name`}},
+		"not in subtree":          {`in:.users status`, status, nil},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			q, err := parseSearch(tc.input)
+			if err != nil {
+				t.Fatalf("parseSearch(%q): %v", tc.input, err)
+			}
+
+			matches, ok := q.match(tc.e)
+			var got []string
+			for _, m := range matches {
+				if m.inKey {
+					got = append(got, "key:"+tc.e.key[m.start:m.end])
+				} else {
+					got = append(got, "value:"+tc.e.value[m.start:m.end])
+				}
+			}
+			if ok != (tc.want != nil) || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("match() = %q, %v, want %q", got, ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	enter := tea.MsgKey{Type: tea.KeyEnter}
+	m := prepareDocument(t, mustJSON(`{"a": {"x": "error"}, "b": 1, "c": ["Error", "ok"]}`))
+	send(m, keys("E")...)
+	send(m, keys("/error")...)
+	send(m, enter)
+
+	// collapsed ancestors of selected result are expanded
+	want := []string{".", ".a", ".a.x", ".a/", ".b", ".c+", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible = %q, want %q", got, want)
+	}
+
+	for i, tc := range []struct {
+		keys   string
+		cursor jsonPath
+		status string
+	}{
+		{"", jsonPath{"a", "x"}, "found: [1/2]"},
+		{"n", jsonPath{"c", 0}, "found: [2/2]"},
+		{"n", jsonPath{"a", "x"}, "found: [1/2]"},
+		{"N", jsonPath{"c", 0}, "found: [2/2]"},
+	} {
+		send(m, keys(tc.keys)...)
+		if got := m.tree.Selected().path; !got.Equal(tc.cursor) {
+			t.Errorf("cursor after %d-th step = %s, want %s", i+1, got, tc.cursor)
+		}
+		if lines := screenLines(m); !strings.HasSuffix(lines[len(lines)-1], tc.status) {
+			t.Errorf("footer after %d-th step = %q, want %q", i+1, lines[len(lines)-1], tc.status)
+		}
+	}
+	want = []string{".", ".a", ".a.x", ".a/", ".b", ".c", ".c[0]", ".c[1]", ".c/", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after moving to second result = %q, want %q", got, want)
+	}
+
+	send(m, keys("/")...)
+	send(m, tea.MsgKey{Type: tea.KeyCtrlU}) // previous search is kept in input
+	send(m, keys("error/")...)
+	send(m, enter)
+	if got := len(m.search.results); got != 1 {
+		t.Errorf("case sensitive search found %d results, want 1", got)
+	}
+
+	send(m, keys("/")...)
+	send(m, tea.MsgKey{Type: tea.KeyCtrlU})
+	send(m, keys("(")...)
+	send(m, enter)
+	if lines := screenLines(m); !strings.Contains(lines[len(lines)-1], "error parsing regexp") {
+		t.Errorf("footer of invalid regex = %q, want error", lines[len(lines)-1])
+	}
+}
+
+func TestSearchQualifiers(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": {"n": 5, "m": "5"}, "c": [{"n": 50}, {"n": 500}]}`))
+	send(m, keys("/in:.c value:>10")...)
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+
+	var got []string
+	for _, r := range m.search.results {
+		got = append(got, r.path.String())
+	}
+	if want := []string{".c[0].n", ".c[1].n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	t.Parallel()
+
+	enter := tea.MsgKey{Type: tea.KeyEnter}
+	results := func(m *model) []string {
+		if m.search == nil {
+			return nil
+		}
+
+		var res []string
+		for _, r := range m.search.results {
+			res = append(res, r.path.String())
+		}
+		return res
+	}
+
+	m := prepareDocument(t, mustJSON(`{"items": [{"id": 1}, {"id": 2}], "n": 0}`))
+	send(m, keys("&.items[].id")...)
+	send(m, enter)
+	if got, want := results(m), []string{".items[0].id", ".items[1].id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+	if got, want := m.tree.Selected().path, (jsonPath{"items", 0, "id"}); !got.Equal(want) {
+		t.Errorf("cursor = %s, want %s", got, want)
+	}
+
+	// query result is not original document, which paths point into
+	send(m, keys(".")...)
+	m.digInput.SetValue(".items")
+	send(m, enter)
+	send(m, keys("&")...)
+	send(m, tea.MsgKey{Type: tea.KeyCtrlU})
+	send(m, keys(".n")...)
+	send(m, enter)
+	if want := "paths are searched in original, open split view with s"; m.search == nil || m.search.err == nil || m.search.err.Error() != want {
+		t.Errorf("search in query result = %v, want error %q", m.search, want)
+	}
+
+	// original is searched in its pane of split view
+	send(m, keys("s&")...)
+	send(m, enter)
+	if !m.sourceFocused {
+		t.Errorf("pane of original is not focused")
+	}
+	if got, want := results(m), []string{".n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("results in split view = %q, want %q", got, want)
+	}
+}
+
+func TestSearchPathsCancel(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	send(m, keys("&")...)
+	m.searchInput.SetValue("last(repeat(.))")
+	cmds := update(m, tea.MsgKey{Type: tea.KeyEnter})
+	if !m.searchRunning() {
+		t.Fatalf("search is not running")
+	}
+
+	send(m, tea.MsgKey{Type: tea.KeyEsc})
+	send(m, cmds[0]())
+	if m.search != nil {
+		t.Errorf("cancelled search is shown: %+v", m.search)
+	}
+}
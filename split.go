@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+	"github.com/rprtr258/tea/styles"
+)
+
+// minSideBySideWidth is terminal width starting from which panes of split view
+// are shown side by side instead of stacked.
+const minSideBySideWidth = 100
+
+// pane is JSON view of document with its own cursor, folds and scroll.
+type pane struct {
+	tree   *hierachy.Hierachy[entry]
+	result any
+	// search is result of last search, nil if there is none
+	search *search
+	// filter is whether tree is pruned to search results, unfiltered holds
+	// folds of whole tree to restore them after filtering
+	filter     bool
+	unfiltered map[string]struct{}
+	// offset is index of first visible node shown in JSON view, colOffset is
+	// number of columns unwrapped lines are scrolled by
+	offset    int
+	colOffset int
+	// root is path of subtree shown as root of view, zoom holds views it was
+	// zoomed from
+	root jsonPath
+	zoom []zoomLevel
+	// decoded tells whether string at path is shown decoded or as is,
+	// strings not in it are decoded only if expandStrings is set
+	decoded map[string]bool
+}
+
+// swapPanes moves focus to other pane.
+func (m *model) swapPanes() {
+	m.pane, m.other = m.other, m.pane
+	m.sourceFocused = !m.sourceFocused
+}
+
+// inOtherPane runs f with other pane in focus.
+func (m *model) inOtherPane(f func()) {
+	m.swapPanes()
+	defer m.swapPanes()
+	f()
+}
+
+// inResultPane runs f with pane of query result in focus.
+func (m *model) inResultPane(f func()) {
+	if m.sourceFocused {
+		m.inOtherPane(f)
+		return
+	}
+	f()
+}
+
+// inSourcePane runs f with pane of original document in focus.
+func (m *model) inSourcePane(f func()) {
+	if !m.sourceFocused {
+		m.inOtherPane(f)
+		return
+	}
+	f()
+}
+
+// sideBySide is whether panes of split view are shown side by side.
+func (m *model) sideBySide() bool {
+	return m.termWidth >= minSideBySideWidth
+}
+
+// paneWidth is number of columns of single pane.
+func (m *model) paneWidth() int {
+	if m.split && m.sideBySide() {
+		return (m.termWidth - 1) / 2
+	}
+	return m.termWidth
+}
+
+// areaHeight is number of lines taken by panes.
+func (m *model) areaHeight() int {
+	return max(1, m.termHeight-2-m.headerHeight())
+}
+
+// viewHeight is number of lines available for JSON view of single pane.
+func (m *model) viewHeight() int {
+	if m.split && !m.sideBySide() {
+		return max(1, (m.areaHeight()-1)/2)
+	}
+	return m.areaHeight()
+}
+
+// toggleSplit shows or hides pane of original document, cursor in it is
+// moved to origin of selected result node.
+func (m *model) toggleSplit() {
+	if m.split {
+		if m.sourceFocused {
+			m.swapPanes()
+		}
+		m.split = false
+		return
+	}
+
+	m.split = true
+	m.inOtherPane(m.reloadSource)
+}
+
+// reloadSource rebuilds tree of original document, which must be in focus,
+// keeping cursor and folds.
+func (m *model) reloadSource() {
+	m.reload(m.original)
+}
+
+// reload shows changed document in pane in focus, keeping cursor and folds.
+func (m *model) reload(v any) {
+	m.result = v
+	if _, ok := m.viewed(m.result); !ok { // zoomed root is gone
+		m.resetZoom()
+	}
+	m.rebuildTree()
+}
+
+// rebuildTree rebuilds tree of pane in focus, keeping cursor and folds.
+func (m *model) rebuildTree() {
+	m.redoSearch()
+	tree := newHierachy(m.newTree(m.result))
+	if m.tree != nil {
+		restoreState(tree, saveState(m.tree))
+	}
+	m.tree = tree
+}
+
+// origins are paths in input of stage of its results, computed in background.
+type origins struct {
+	paths []jsonPath
+	// done is whether paths are computed, cancel stops computing them
+	done   bool
+	cancel context.CancelFunc
+}
+
+// msgOrigins is result of computing origins of stage.
+type msgOrigins struct {
+	origins *origins
+	paths   []jsonPath
+}
+
+// originOf returns path in original document of node at path p of query
+// result, if it is taken from original document as is by every stage of
+// pipeline. Origins not computed yet are started to be computed in
+// background, ok is false until they are.
+func (m *model) originOf(p jsonPath, yield func(...tea.Cmd)) (jsonPath, bool) {
+	p, ok := m.stageOrigin(&m.stage, m.stageInput(len(m.stages)), p, yield)
+	for i := len(m.stages) - 1; ok && i >= 0; i-- {
+		p, ok = m.stageOrigin(&m.stages[i], m.stageInput(i), p, yield)
+	}
+	return p, ok
+}
+
+// stageOrigin returns path in input of stage of node at path p of its result.
+func (m *model) stageOrigin(s *stage, input any, p jsonPath, yield func(...tea.Cmd)) (jsonPath, bool) {
+	if s.origins == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		o := &origins{paths: nil, done: false, cancel: cancel}
+		s.origins = o
+		program, timeout, limit := "path("+s.query+")", m.queryTimeout, m.queryLimit
+		yield(func() tea.Msg {
+			paths, _ := runPaths(ctx, program, input, timeout, limit)
+			return msgOrigins{origins: o, paths: paths}
+		})
+	}
+
+	origins := s.origins.paths
+	switch {
+	case !s.origins.done:
+		return nil, false
+	case len(origins) == 1 && s.stream == nil: // single result
+		if origins[0] == nil {
+			return nil, false
+		}
+		return append(origins[0][:len(origins[0]):len(origins[0])], p...), true
+	case len(p) > 0:
+		i, ok := p[0].(int)
+		if !ok || i >= len(origins) || origins[i] == nil {
+			return nil, false
+		}
+
+		origin := origins[i]
+		return append(origin[:len(origin):len(origin)], p[1:]...), true
+	default:
+		return nil, false
+	}
+}
+
+// handleOrigins keeps computed origins, source is synced to them after
+// update.
+func (m *model) handleOrigins(msg msgOrigins) {
+	o := msg.origins
+	o.cancel()
+	o.paths, o.done = msg.paths, true
+}
+
+// stopOrigins drops origins of stage, cancelling computing them.
+func (s *stage) stopOrigins() {
+	if s.origins != nil {
+		s.origins.cancel()
+		s.origins = nil
+	}
+}
+
+// syncSource moves cursor of original document pane to origin of node
+// selected in result pane and keeps cursor of pane not in focus visible.
+func (m *model) syncSource(yield func(...tea.Cmd)) {
+	if !m.split {
+		return
+	}
+
+	var origin jsonPath
+	ok := false
+	if !m.sourceFocused {
+		origin, ok = m.originOf(m.tree.Selected().path.Value(), yield)
+	}
+
+	m.inOtherPane(func() {
+		if ok {
+			selectPath(m.tree, origin)
+		}
+		m.scrollIntoView()
+	})
+}
+
+// paneAt returns whether column x and line y of panes area are in pane of
+// original document and position relative to pane, ok is false on separator
+// between panes.
+func (m *model) paneAt(x, y int) (source bool, px, py int, ok bool) {
+	switch {
+	case m.sideBySide() && x == m.paneWidth(), !m.sideBySide() && y == m.viewHeight():
+		return false, 0, 0, false
+	case m.sideBySide() && x > m.paneWidth():
+		return false, x - m.paneWidth() - 1, y, true
+	case !m.sideBySide() && y > m.viewHeight():
+		return false, x, y - m.viewHeight() - 1, true
+	default:
+		return true, x, y, true
+	}
+}
+
+// viewPane shows JSON view of pane in focus. Boxes are cut with paddings,
+// since splits place parts at origin of the screen instead of the box.
+func (m *model) viewPane(vb tea.Viewbox) {
+	vbJSON := vb.PaddingLeft(m.gutterWidth())
+	if m.gutter != gutterOff {
+		m.viewGutter(vb.Sub(tea.Rectangle{Width: m.gutterWidth()}))
+	}
+	m.viewJSON(vbJSON)
+	m.viewSticky(vbJSON)
+}
+
+// viewSplit shows original document and query result, source on the left or
+// on top.
+func (m *model) viewSplit(vb tea.Viewbox) {
+	var vbSource, vbResult tea.Viewbox
+	vbSeparator := vb.Styled(styles.Style{}.Foreground(currentTheme.Preview))
+	if w := m.paneWidth(); m.sideBySide() {
+		vbSource, vbResult = vb.Sub(tea.Rectangle{Width: w}), vb.PaddingLeft(w+1)
+		for y := 0; y < vb.Height; y++ {
+			vbSeparator.Row(y).PaddingLeft(w).WriteLine("│")
+		}
+	} else {
+		h := m.viewHeight()
+		vbSource, vbResult = vb.Sub(tea.Rectangle{Height: h}), vb.PaddingTop(h+1)
+		vbSeparator.Row(h).WriteLine(strings.Repeat("─", vb.Width))
+	}
+
+	if m.sourceFocused {
+		m.viewPane(vbSource)
+		m.inOtherPane(func() { m.viewPane(vbResult) })
+	} else {
+		m.viewPane(vbResult)
+		m.inOtherPane(func() { m.viewPane(vbSource) })
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rprtr258/tea"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": {"b": 1}, "c": [1, 2]}`))
+	dig(m, ".c")
+	send(m, keys("sjj")...)
+	if !m.split || m.sourceFocused {
+		t.Fatalf("split %v, source focused %v, want result pane in focus", m.split, m.sourceFocused)
+	}
+
+	// source follows cursor of result
+	if got, want := m.other.tree.Selected().path, (jsonPath{"c", 1}); !got.Equal(want) {
+		t.Errorf("source cursor = %s, want %s", got, want)
+	}
+
+	// result does not follow cursor of source
+	send(m, keys("wgg")...)
+	if !m.sourceFocused || !reflect.DeepEqual(m.result, m.original) {
+		t.Errorf("source pane is not focused")
+	}
+	if got, want := m.other.tree.Selected().path, (jsonPath{1}); !got.Equal(want) {
+		t.Errorf("result cursor = %s, want %s", got, want)
+	}
+
+	// closing split focuses result
+	send(m, keys("s")...)
+	if m.split || m.sourceFocused || m.query != ".c" {
+		t.Errorf("split %v, source focused %v, query %q after closing split", m.split, m.sourceFocused, m.query)
+	}
+}
+
+func TestSplitDecoded(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": "eyJiIjoxfQ==", "c": 1}`))
+	send(m, keys("sjx")...)
+	if want := []string{".", ".a", ".a.b", ".a/", ".c", "./"}; !reflect.DeepEqual(visiblePaths(m.tree), want) {
+		t.Errorf("result = %q, want %q", visiblePaths(m.tree), want)
+	}
+
+	// decoding in one pane does not change other one
+	if want := []string{".", ".a", ".c", "./"}; !reflect.DeepEqual(visiblePaths(m.other.tree), want) {
+		t.Errorf("source = %q, want %q", visiblePaths(m.other.tree), want)
+	}
+}
+
+func TestStageOrigin(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		paths  []jsonPath
+		stream bool
+		p      jsonPath
+		want   jsonPath
+		ok     bool
+	}{
+		"single result": {
+			paths: []jsonPath{{"users", 0}},
+			p:     jsonPath{"name"},
+			want:  jsonPath{"users", 0, "name"},
+			ok:    true,
+		},
+		"single result root": {
+			paths: []jsonPath{{"users", 0}},
+			p:     jsonPath{},
+			want:  jsonPath{"users", 0},
+			ok:    true,
+		},
+		"single result not selected by path": {
+			paths: []jsonPath{nil},
+			p:     jsonPath{"name"},
+			ok:    false,
+		},
+		"stream result": {
+			paths:  []jsonPath{{"users", 0, "name"}, {"users", 1, "name"}},
+			stream: true,
+			p:      jsonPath{1},
+			want:   jsonPath{"users", 1, "name"},
+			ok:     true,
+		},
+		"stream of one result": {
+			paths:  []jsonPath{{"users", 0}},
+			stream: true,
+			p:      jsonPath{0, "name"},
+			want:   jsonPath{"users", 0, "name"},
+			ok:     true,
+		},
+		"stream root": {
+			paths:  []jsonPath{{"users", 0}, {"users", 1}},
+			stream: true,
+			p:      jsonPath{},
+			ok:     false,
+		},
+		"stream result missing": {
+			paths:  []jsonPath{{"users", 0}},
+			stream: true,
+			p:      jsonPath{3},
+			ok:     false,
+		},
+		"stream result not selected by path": {
+			paths:  []jsonPath{{"users", 0}, nil},
+			stream: true,
+			p:      jsonPath{1},
+			ok:     false,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &stage{origins: &origins{paths: tc.paths, done: true, cancel: func() {}}}
+			if tc.stream {
+				s.stream = &resultStream{}
+			}
+			yield := func(...tea.Cmd) { t.Fatal("origins are computed again") }
+
+			m := &model{}
+			got, ok := m.stageOrigin(s, nil, tc.p, yield)
+			if ok != tc.ok || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("stageOrigin(%v) = %v, %v, want %v, %v", tc.p, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestStageOriginPending(t *testing.T) {
+	t.Parallel()
+
+	s := &stage{}
+	s.query = ".users[]"
+	var cmds []tea.Cmd
+	yield := func(c ...tea.Cmd) { cmds = append(cmds, c...) }
+
+	m := &model{queryTimeout: defaultQueryTimeout, queryLimit: defaultQueryLimit}
+	input := mustJSON(`{"users": [1, 2]}`)
+	if _, ok := m.stageOrigin(s, input, jsonPath{1}, yield); ok || len(cmds) != 1 {
+		t.Fatalf("stageOrigin() = %v with %d commands, want pending computation", ok, len(cmds))
+	}
+	if _, ok := m.stageOrigin(s, input, jsonPath{1}, yield); ok || len(cmds) != 1 {
+		t.Fatalf("stageOrigin() = %v with %d commands, want computation started once", ok, len(cmds))
+	}
+
+	m.handleOrigins(cmds[0]().(msgOrigins))
+	got, ok := m.stageOrigin(s, input, jsonPath{1}, yield)
+	if want := (jsonPath{"users", 1}); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("stageOrigin() = %v, %v, want %v, true", got, ok, want)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/styles"
+)
+
+func (k elemKind) String() string {
+	switch k {
+	case elemKindObject:
+		return "object"
+	case elemKindArray:
+		return "array"
+	case elemKindNull:
+		return "null"
+	case elemKindNumber:
+		return "number"
+	case elemKindString:
+		return "string"
+	case elemKindBool:
+		return "bool"
+	default:
+		return "unknown"
+	}
+}
+
+// formatSize formats number of bytes with binary prefix, e.g. 1.5 KiB.
+func formatSize(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	size := float64(n) / 1024
+	for _, unit := range []string{"KiB", "MiB", "GiB"} {
+		if size < 1024 || unit == "GiB" {
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+		size /= 1024
+	}
+	panic("unreachable")
+}
+
+// selectedInfo describes type of selected node and number of its children.
+func (m *model) selectedInfo() string {
+	selected := m.tree.Selected()
+	n := selected.count
+	switch selected.kind {
+	case elemKindObject:
+		return fmt.Sprintf("object, %d %s", n, fun.IF(n == 1, "key", "keys"))
+	case elemKindArray:
+		return fmt.Sprintf("array, %d %s", n, fun.IF(n == 1, "item", "items"))
+	default:
+		return selected.kind.String()
+	}
+}
+
+// viewStatusBar shows path of selected node on the left, and input name, node
+// info and position on the right, as much of them as fits.
+func (m *model) viewStatusBar(vb tea.Viewbox) {
+	vb = vb.Styled(styles.Style{}.Background(currentTheme.StatusBar))
+	path := m.tree.Selected().path.String()
+	x := vb.WriteLine(path)
+
+	parts := []string{m.fileName}
+	if m.split {
+		parts = append(parts, fun.IF(m.sourceFocused, "original", "result"))
+	}
+	if m.stream != nil && !m.sourceFocused {
+		parts = append(parts, m.stream.String())
+	}
+	parts = append(parts,
+		m.selectedInfo(),
+		formatSize(m.tree.Selected().size),
+		fmt.Sprintf("%d/%d", cursorIndex(m.tree)+1, visibleCount(m.tree)),
+	)
+	for len(parts) > 0 {
+		info := strings.Join(parts, "  ")
+		if w := runewidth.StringWidth(info); x+1+w <= vb.Width {
+			vb.PaddingLeft(vb.Width - w).WriteLine(info)
+			return
+		}
+		parts = parts[1:]
+	}
+}
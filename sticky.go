@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/styles"
+)
+
+// viewWindow returns visible nodes shown in JSON view.
+func (m *model) viewWindow() window {
+	return visibleWindow(m.tree, m.offset, m.offset+m.viewHeight())
+}
+
+// nodeAtLine returns index of node shown at given line of JSON view, or -1 if
+// there is no such node.
+func (m *model) nodeAtLine(w window, heights func(int) int, line int) int {
+	for i := m.offset; i < w.end(); i++ {
+		if line < heights(i) {
+			return i
+		}
+		line -= heights(i)
+	}
+	return -1
+}
+
+// stickyAncestors returns indices of nodes pinned at top of JSON view. Those
+// are ancestors of first node not covered by them, which are scrolled out of
+// view or covered themselves. At most half of view is taken by them.
+func (m *model) stickyAncestors(w window, heights func(int) int) []int {
+	var sticky []int
+	for {
+		top := m.nodeAtLine(w, heights, len(sticky))
+		if top == -1 {
+			return sticky
+		}
+
+		chain := w.ancestors(top)
+		chain = chain[max(0, len(chain)-m.viewHeight()/2):]
+		if len(chain) <= len(sticky) {
+			return chain
+		}
+		sticky = chain
+	}
+}
+
+// viewSticky draws sticky ancestors over first lines of JSON view.
+func (m *model) viewSticky(vb tea.Viewbox) {
+	w := m.viewWindow()
+	for row, i := range m.stickyAncestors(w, m.lineHeights(w)) {
+		item := w.item(i)
+		vbRow := vb.Row(row)
+		vbRow.Fill(' ')
+
+		label := ""
+		switch part := item.Value.path[len(item.Value.path)-1].(type) {
+		case string:
+			label = item.Value.key + ": "
+		case int:
+			label = "[" + strconv.Itoa(part) + "] "
+		}
+		x := m.layoutOf(item, vb.Width).indent - m.colOffset
+		x = writeAt(vbRow.Styled(styles.Style{}.Foreground(currentTheme.Key)), x, label)
+		writeAt(vbRow.Styled(styles.Style{}.Foreground(currentTheme.Preview)), x, fun.IF(item.Value.kind == elemKindObject, "{", "["))
+	}
+}
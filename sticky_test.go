@@ -0,0 +1,44 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rprtr258/tea"
+)
+
+// reEscape matches terminal escape sequences of rendered screen.
+var reEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// screenLines returns lines of rendered screen without styles and trailing
+// spaces.
+func screenLines(m *model) []string {
+	lines := strings.Split(string(reEscape.ReplaceAll(render(m), nil)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return lines
+}
+
+func TestSticky(t *testing.T) {
+	t.Parallel()
+
+	numbers := make([]any, 100)
+	for i := range numbers {
+		numbers[i] = float64(i)
+	}
+	m := prepareDocument(t, map[string]any{"a": map[string]any{"b": numbers}})
+
+	lines := screenLines(m)
+	if want := []string{"{", `  "a": {`, `    "b": [`, "      0"}; !slices.Equal(lines[:4], want) {
+		t.Errorf("screen starts with %q, want %q", lines[:4], want)
+	}
+
+	send(m, tea.MsgKey{Type: tea.KeyPgDown})
+	lines = screenLines(m)
+	if want := []string{`  "a": {`, `    "b": [`, "      35"}; !slices.Equal(lines[:3], want) {
+		t.Errorf("scrolled screen starts with %q, want ancestors pinned %q", lines[:3], want)
+	}
+}
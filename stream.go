@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea"
+)
+
+const (
+	// queryPageSize is max number of results fetched at once
+	queryPageSize = 100
+	// queryPageTime is time after which fetched results are shown, even if
+	// page is not full yet
+	queryPageTime = 200 * time.Millisecond
+)
+
+// resultPage is part of results of jq program. Done is set when iterator is
+// exhausted, limited when result limit is reached, err when iterator failed,
+// no more results are fetched then.
+type resultPage struct {
+	values  []any
+	done    bool
+	limited bool
+	err     error
+}
+
+// resultStream is iterator over results of jq program, which are fetched page
+// by page as cursor approaches end of the list. Fetching is done in
+// background, while other fields are changed only when page is added.
+type resultStream struct {
+	iter   gojq.Iter
+	cancel context.CancelFunc
+	// count is number of fetched results, fetching is set while next page is
+	// fetched
+	count    int
+	fetching bool
+	done     bool
+	limited  bool
+	err      error
+	// paused is set while stage showing results is not shown, no more of
+	// them are fetched then, page fetched when it was left is kept in pending
+	paused  bool
+	pending *resultPage
+}
+
+// msgQueryPage is next page of results of shown query.
+type msgQueryPage struct {
+	stream *resultStream
+	page   resultPage
+}
+
+// startStream runs jq program over original document, results are computed
+// when fetched.
+func startStream(program string, original any) (*resultStream, error) {
+	code, err := compileQuery(program)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &resultStream{
+		iter:     code.RunWithContext(ctx, original),
+		cancel:   cancel,
+		count:    0,
+		fetching: false,
+		done:     false,
+		limited:  false,
+		err:      nil,
+		paused:   false,
+		pending:  nil,
+	}, nil
+}
+
+// fetch reads next page of at least min results, unless stream ends earlier.
+// Fetching fails if it takes longer than timeout.
+func (s *resultStream) fetch(min int, timeout time.Duration, limit int) resultPage {
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		s.cancel()
+	})
+	defer timer.Stop()
+
+	start := time.Now()
+	var page resultPage
+	for len(page.values) < queryPageSize && (len(page.values) < min || time.Since(start) < queryPageTime) {
+		if limit > 0 && s.count+len(page.values) == limit {
+			page.limited = true
+			break
+		}
+
+		v, ok := s.iter.Next()
+		if !ok {
+			page.done = true
+			break
+		}
+
+		if err, ok := v.(error); ok {
+			if timedOut.Load() {
+				err = fmt.Errorf("query timed out after %v", timeout)
+			}
+			page.err = fmt.Errorf("execute: %w", err)
+			break
+		}
+
+		page.values = append(page.values, v)
+	}
+	if page.done || page.limited || page.err != nil {
+		s.cancel()
+	}
+	return page
+}
+
+// add counts fetched page and remembers why stream has ended.
+func (s *resultStream) add(page resultPage) {
+	s.count += len(page.values)
+	s.done, s.limited, s.err = page.done, page.limited, page.err
+}
+
+// more is whether more results can be fetched.
+func (s *resultStream) more() bool {
+	return !s.done && !s.limited && s.err == nil && !s.paused
+}
+
+// pause stops fetching results until stream is resumed.
+func (s *resultStream) pause() {
+	s.paused = true
+}
+
+// replayStream runs jq program over changed input, fetching at least count
+// results page by page like shown ones, so that results which were not
+// fetched are not computed. Result is single value if program has output
+// exactly one, stream is nil then.
+func replayStream(program string, input any, count int, timeout time.Duration, limit int) (any, *resultStream, error) {
+	s, err := startStream(program, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// two results are needed to tell single result from list
+	var values []any
+	for s.more() && s.count < max(count, 2) {
+		page := s.fetch(max(count, 2)-s.count, timeout, limit)
+		s.add(page)
+		values = append(values, page.values...)
+	}
+	switch {
+	case s.err != nil:
+		return nil, nil, s.err
+	case s.done && len(values) == 1:
+		return values[0], nil, nil
+	default:
+		return append([]any{}, values...), s, nil
+	}
+}
+
+// String describes number of fetched results, e.g. 100+ results.
+func (s *resultStream) String() string {
+	n := fmt.Sprint(s.count)
+	if !s.done {
+		n += "+"
+	}
+	n += fun.IF(s.count == 1 && s.done, " result", " results")
+	if s.limited {
+		n += ", limit reached"
+	}
+	return n
+}
+
+// fetchMore fetches next page of results of shown query in background, when
+// cursor is less than two screens away from end of the list.
+func (m *model) fetchMore(yield func(...tea.Cmd)) {
+	s := m.stream
+	if s == nil || m.sourceFocused || !s.more() || s.fetching ||
+		visibleCount(m.tree)-cursorIndex(m.tree) > 2*m.viewHeight() {
+		return
+	}
+
+	s.fetching = true
+	m.cancelQuery = s.cancel
+	m.queryStart = time.Now()
+	timeout, limit := m.queryTimeout, m.queryLimit
+	yield(func() tea.Msg {
+		return msgQueryPage{
+			stream: s,
+			page:   s.fetch(1, timeout, limit),
+		}
+	}, m.cmdSpinner())
+}
+
+// handleQueryPage appends fetched results to shown ones.
+func (m *model) handleQueryPage(msg msgQueryPage) {
+	if msg.stream != m.stream {
+		if msg.stream.paused { // stage was left while page was fetched
+			msg.stream.pending = &msg.page
+		}
+		return
+	}
+
+	m.cancelQuery = nil
+	m.queryEnd = time.Now()
+	msg.stream.fetching = false
+	msg.stream.add(msg.page)
+	if msg.page.err != nil && m.queryError == "" {
+		m.queryError = msg.page.err.Error()
+	}
+	if len(msg.page.values) == 0 {
+		return
+	}
+
+	m.result = append(m.result.([]any), msg.page.values...)
+	m.redoSearch()
+	state := saveState(m.tree)
+	m.tree = newHierachy(m.newTree(m.result))
+	restoreState(m.tree, state)
+}
+
+// resumeStream continues fetching results of shown stage, page fetched while
+// it was not shown is added first.
+func (m *model) resumeStream() {
+	s := m.stream
+	if s == nil {
+		return
+	}
+
+	s.paused = false
+	if page := s.pending; page != nil {
+		s.pending = nil
+		m.handleQueryPage(msgQueryPage{stream: s, page: *page})
+	}
+}
+
+// stopStream stops fetching results of shown query.
+func (m *model) stopStream() {
+	if m.stream != nil {
+		m.stream.cancel()
+		m.stream = nil
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rprtr258/tea"
+)
+
+// dig applies jq program to shown document.
+func dig(m *model, program string) {
+	send(m, keys(".")...)
+	m.digInput.SetValue(program)
+	send(m, tea.MsgKey{Type: tea.KeyEnter})
+}
+
+func TestFetchMore(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	dig(m, "range(250)")
+	for i, tc := range []struct {
+		keys   string
+		count  int
+		cursor int
+		stream string
+	}{
+		{"", queryPageSize, 0, "100+ results"},
+		{"j", queryPageSize, 1, "100+ results"},
+		// cursor is kept on closing bracket, next page is added before it
+		{"G", 2 * queryPageSize, 201, "200+ results"},
+		{"G", 250, 251, "250 results"},
+		{"G", 250, 251, "250 results"},
+	} {
+		send(m, keys(tc.keys)...)
+		if got := len(m.result.([]any)); got != tc.count {
+			t.Errorf("results after %d-th step = %d, want %d", i+1, got, tc.count)
+		}
+		if got := cursorIndex(m.tree); got != tc.cursor {
+			t.Errorf("cursor after %d-th step = %d, want %d", i+1, got, tc.cursor)
+		}
+		if got := m.stream.String(); got != tc.stream {
+			t.Errorf("stream after %d-th step = %q, want %q", i+1, got, tc.stream)
+		}
+	}
+	if m.cancelQuery != nil || m.stream.fetching {
+		t.Errorf("ended stream is fetched")
+	}
+}
+
+func TestFetchMoreError(t *testing.T) {
+	t.Parallel()
+
+	m := prepare(t)
+	dig(m, `range(150), error("boom")`)
+	send(m, keys("G")...)
+	if got := len(m.result.([]any)); got != 150 {
+		t.Errorf("results = %d, want 150", got)
+	}
+	if want := "execute: error: boom"; m.queryError != want {
+		t.Errorf("error = %q, want %q", m.queryError, want)
+	}
+	if m.stream.more() {
+		t.Errorf("failed stream can fetch more")
+	}
+}
+
+func TestFetchMoreWatch(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"n": 250}`))
+	m.watchCommand = `echo '{"n": 300}'`
+	m.watchInterval = time.Millisecond
+	dig(m, "range(.n)")
+	cmds := update(m, keys("G")[0])
+	if m.cancelQuery == nil {
+		t.Fatalf("next page is not fetched")
+	}
+
+	// stream is replaced, so its page is dropped and query is not running
+	update(m, m.cmdWatch()())
+	if m.cancelQuery != nil {
+		t.Errorf("query is running after watched document has changed")
+	}
+	send(m, cmds[0]())
+	if got := m.stream.String(); got != "100+ results" {
+		t.Errorf("stream = %q, want 100+ results", got)
+	}
+
+	send(m, keys("G")...)
+	if got := m.stream.String(); got != "200+ results" {
+		t.Errorf("stream = %q, want 200+ results", got)
+	}
+}
@@ -21,6 +21,9 @@ type theme struct {
 	Null      scuf.Modifier
 	Bool      scuf.Modifier
 	Number    scuf.Modifier
+	Added     scuf.Modifier
+	Removed   scuf.Modifier
+	Changed   scuf.Modifier
 }
 
 func isDigit(ch rune) bool {
@@ -59,6 +62,17 @@ func valueStyle(bb *string, selected, chunk bool) scuf.Modifier {
 	}
 }
 
+func diffStyle(diff diffKind) scuf.Modifier {
+	switch diff {
+	case diffAdded:
+		return currentTheme.Added
+	case diffRemoved:
+		return currentTheme.Removed
+	default:
+		return currentTheme.Changed
+	}
+}
+
 func or(s1, s2 string) string {
 	if s1 != "" {
 		return s1
@@ -93,6 +107,9 @@ var (
 	defaultStatusBar = scuf.Combine(scuf.BgANSI(7), scuf.FgANSI(0))
 	defaultSearch    = scuf.Combine(scuf.BgANSI(11), scuf.FgANSI(16))
 	defaultNull      = scuf.FgANSI(243)
+	defaultAdded     = scuf.BgANSI(22)
+	defaultRemoved   = scuf.BgANSI(52)
+	defaultChanged   = scuf.BgANSI(58)
 )
 
 var (
@@ -117,6 +134,9 @@ var themes = map[string]theme{
 		Null:      nil,
 		Bool:      nil,
 		Number:    nil,
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"1": {
 		Cursor:    defaultCursor,
@@ -129,6 +149,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgANSI(5),
 		Number:    scuf.FgANSI(6),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"2": {
 		Cursor:    defaultCursor,
@@ -141,6 +164,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgANSI(5),
 		Number:    scuf.FgANSI(6),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"3": {
 		Cursor:    defaultCursor,
@@ -153,6 +179,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgANSI(1),
 		Number:    scuf.FgANSI(14),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"4": {
 		Cursor:    defaultCursor,
@@ -165,6 +194,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgRGB(scuf.MustParseHexRGB("#F15BB5")),
 		Number:    scuf.FgRGB(scuf.MustParseHexRGB("#9B5DE5")),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"5": {
 		Cursor:    defaultCursor,
@@ -177,6 +209,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgRGB(scuf.MustParseHexRGB("#ee964b")),
 		Number:    scuf.FgRGB(scuf.MustParseHexRGB("#ee964b")),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"6": {
 		Cursor:    defaultCursor,
@@ -189,6 +224,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgRGB(scuf.MustParseHexRGB("#FF6B6B")),
 		Number:    scuf.FgRGB(scuf.MustParseHexRGB("#FFD93D")),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"7": {
 		Cursor:    defaultCursor,
@@ -201,6 +239,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      bold(scuf.FgANSI(201)),
 		Number:    bold(scuf.FgANSI(201)),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"8": {
 		Cursor:    defaultCursor,
@@ -213,6 +254,9 @@ var themes = map[string]theme{
 		Null:      defaultNull,
 		Bool:      scuf.FgANSI(50),
 		Number:    scuf.FgANSI(123),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"🔵": {
 		Cursor:    scuf.Combine(scuf.FgANSI(15), scuf.BgANSI(33)),
@@ -225,6 +269,9 @@ var themes = map[string]theme{
 		Null:      nil,
 		Bool:      nil,
 		Number:    nil,
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 	"🥝": {
 		Cursor:    defaultCursor,
@@ -237,6 +284,9 @@ var themes = map[string]theme{
 		Null:      scuf.FgANSI(230),
 		Bool:      scuf.FgANSI(226),
 		Number:    scuf.FgANSI(226),
+		Added:     defaultAdded,
+		Removed:   defaultRemoved,
+		Changed:   defaultChanged,
 	},
 }
 
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+// withClosingBrackets adds closing bracket line as last child of every
+// non-empty object and array.
+func withClosingBrackets(node hierachy.Node[entry]) hierachy.Node[entry] {
+	if len(node.Children) == 0 {
+		return node
+	}
+
+	children := make([]hierachy.Node[entry], 0, len(node.Children)+1)
+	for _, child := range node.Children {
+		children = append(children, withClosingBrackets(child))
+	}
+	children = append(children, hierachy.Node[entry]{
+		Value: entry{
+			kind:    node.Value.kind,
+			value:   fun.IF(node.Value.kind == elemKindObject, "}", "]"),
+			path:    node.Value.path.Closing(),
+			diff:    fun.IF(node.Value.diff == diffChanged, diffNone, node.Value.diff),
+			closing: true,
+			size:    node.Value.size,
+			count:   node.Value.count,
+		},
+		Children: nil,
+	})
+	node.Children = children
+	return node
+}
+
+func newHierachy(node hierachy.Node[entry]) *hierachy.Hierachy[entry] {
+	return hierachy.New(withClosingBrackets(node))
+}
+
+// visibleItems returns nodes which are not hidden by collapsed ancestors.
+func visibleItems(tree *hierachy.Hierachy[entry]) []hierachy.IterItem[entry] {
+	var items []hierachy.IterItem[entry]
+	tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// window is part of visible nodes, from first one up to end, with ancestors
+// of them. It is used to lay out view without walking all nodes of large
+// documents.
+type window struct {
+	first int
+	items []hierachy.IterItem[entry]
+	// parents maps index of node in window or of its ancestor to index of
+	// its parent, -1 for root. outer holds ancestors before window.
+	parents map[int]int
+	outer   map[int]hierachy.IterItem[entry]
+}
+
+// visibleWindow returns visible nodes with indices from first to last,
+// excluding last.
+func visibleWindow(tree *hierachy.Hierachy[entry], first, last int) window {
+	w := window{
+		first:   first,
+		items:   nil,
+		parents: map[int]int{},
+		outer:   map[int]hierachy.IterItem[entry]{},
+	}
+	// stack holds indices of containers current node is inside of
+	var stack []int
+	var stackItems []hierachy.IterItem[entry]
+	i := 0
+	tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		if i >= last {
+			return false
+		}
+
+		for len(stack) > 0 && stackItems[len(stack)-1].Depth >= item.Depth {
+			stack, stackItems = stack[:len(stack)-1], stackItems[:len(stackItems)-1]
+		}
+		if i >= first {
+			w.items = append(w.items, item)
+			w.parents[i] = -1
+			if len(stack) > 0 {
+				w.parents[i] = stack[len(stack)-1]
+			}
+			// remember ancestors before window, unless already known
+			for k := len(stack) - 1; k >= 0 && stack[k] < first; k-- {
+				if _, ok := w.outer[stack[k]]; ok {
+					break
+				}
+
+				w.outer[stack[k]] = stackItems[k]
+				w.parents[stack[k]] = -1
+				if k > 0 {
+					w.parents[stack[k]] = stack[k-1]
+				}
+			}
+		}
+		if item.HasChildren && !item.Value.closing {
+			stack, stackItems = append(stack, i), append(stackItems, item)
+		}
+		i++
+		return true
+	})
+	return w
+}
+
+// end returns index after last node of window.
+func (w window) end() int {
+	return w.first + len(w.items)
+}
+
+// item returns i-th visible node, which must be in window or be ancestor of
+// node in it.
+func (w window) item(i int) hierachy.IterItem[entry] {
+	if i >= w.first && i < w.end() {
+		return w.items[i-w.first]
+	}
+	return w.outer[i]
+}
+
+// ancestors returns indices of visible ancestors of i-th visible node, except
+// root, outermost first.
+func (w window) ancestors(i int) []int {
+	var res []int
+	for j := w.parents[i]; j != -1 && w.item(j).Depth > 0; j = w.parents[j] {
+		res = append([]int{j}, res...)
+	}
+	return res
+}
+
+// siblingIndex returns index of next sibling of i-th visible node, or
+// previous one if dir is negative. Returns -1 if there is no such sibling.
+func siblingIndex(items []hierachy.IterItem[entry], i, dir int) int {
+	for j := i + dir; j >= 0 && j < len(items); j += dir {
+		switch {
+		case items[j].Depth < items[i].Depth:
+			return -1
+		case items[j].Depth == items[i].Depth && !items[j].Value.closing:
+			return j
+		}
+	}
+	return -1
+}
+
+// subtreeEnd returns index of last visible node in subtree of i-th visible
+// node, which is closing bracket for expanded containers.
+func subtreeEnd(items []hierachy.IterItem[entry], i int) int {
+	j := i + 1
+	for j < len(items) && items[j].Depth > items[i].Depth {
+		j++
+	}
+	return j - 1
+}
+
+// cursorIndex returns index of selected node among visible ones.
+func cursorIndex(tree *hierachy.Hierachy[entry]) int {
+	i := 0
+	tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		if item.IsSelected {
+			return false
+		}
+		i++
+		return true
+	})
+	return i
+}
+
+// visibleCount returns number of nodes which are not hidden by collapsed
+// ancestors.
+func visibleCount(tree *hierachy.Hierachy[entry]) int {
+	n := 0
+	tree.Iter(func(hierachy.IterItem[entry]) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// moveCursor moves selection n visible nodes down, or up if n is negative.
+func moveCursor(tree *hierachy.Hierachy[entry], n int) {
+	for ; n > 0; n-- {
+		tree.GoNextOrUp()
+	}
+	for ; n < 0; n++ {
+		tree.GoPrevOrUp()
+	}
+}
+
+// selectClosest moves cursor to deepest visible node on path p.
+func selectClosest(tree *hierachy.Hierachy[entry], p jsonPath) hierachy.IterItem[entry] {
+	target, i := -1, 0
+	var targetItem hierachy.IterItem[entry]
+	tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		if p.HasPrefix(item.Value.path) && (target == -1 || len(item.Value.path) > len(targetItem.Value.path)) {
+			target, targetItem = i, item
+		}
+		i++
+		return true
+	})
+	if target != -1 {
+		moveCursor(tree, target-cursorIndex(tree))
+	}
+	return targetItem
+}
+
+// selectPath moves cursor to node at path p, expanding its collapsed
+// ancestors. If there is no such node, closest existing ancestor is selected.
+func selectPath(tree *hierachy.Hierachy[entry], p jsonPath) {
+	for {
+		item := selectClosest(tree, p)
+		if item.Value.path.Equal(p) || !item.HasChildren || !item.IsCollapsed {
+			return
+		}
+
+		tree.ToggleCollapsed()
+	}
+}
+
+// expandRecursively expands selected node and all its descendants.
+func expandRecursively(tree *hierachy.Hierachy[entry]) {
+	start, root := cursorIndex(tree), tree.Selected().path
+	for {
+		if tree.IsCollapsed() {
+			tree.ToggleCollapsed()
+		}
+
+		current := tree.Selected().path
+		tree.GoNextOrUp()
+		if next := tree.Selected().path; next.Equal(current) || !next.HasPrefix(root) {
+			break
+		}
+	}
+	moveCursor(tree, start-cursorIndex(tree))
+}
+
+// collapseRecursively collapses descendants of selected node which are at
+// least levels below it, nodes above them are expanded.
+func collapseRecursively(tree *hierachy.Hierachy[entry], levels int) {
+	expandRecursively(tree)
+	start, root := cursorIndex(tree), tree.Selected().path
+	// go to last node of subtree, nodes are not collected as there can be
+	// lots of them
+	end := start
+	for {
+		current := tree.Selected().path
+		tree.GoNextOrUp()
+		next := tree.Selected().path
+		if next.Equal(current) {
+			break
+		}
+		if !next.HasPrefix(root) {
+			tree.GoPrevOrUp()
+			break
+		}
+		end++
+	}
+	// going backwards, so collapsing node does not move nodes yet to visit
+	for i := end; i >= start; i-- {
+		if len(tree.Selected().path)-len(root) >= levels && !tree.IsCollapsed() {
+			tree.ToggleCollapsed()
+		}
+		if i > start {
+			tree.GoPrevOrUp()
+		}
+	}
+}
+
+// treeState is cursor position and folds of a tree, addressed by paths so it
+// can be applied to a tree built from a changed document.
+type treeState struct {
+	cursor    jsonPath
+	collapsed map[string]struct{}
+}
+
+func saveState(tree *hierachy.Hierachy[entry]) treeState {
+	state := treeState{
+		cursor:    nil,
+		collapsed: map[string]struct{}{},
+	}
+	tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		if item.IsSelected {
+			state.cursor = item.Value.path
+		}
+		if item.HasChildren && item.IsCollapsed {
+			state.collapsed[item.Value.path.String()] = struct{}{}
+		}
+		return true
+	})
+	return state
+}
+
+// restoreState applies state to freshly built tree.
+func restoreState(tree *hierachy.Hierachy[entry], state treeState) {
+	// fresh tree has every node expanded, so stepping forward visits all of
+	// them, except children of nodes collapsed on the way
+	for {
+		current := tree.Selected().path
+		if _, ok := state.collapsed[current.String()]; ok {
+			tree.ToggleCollapsed()
+		}
+
+		tree.GoNextOrUp()
+		if tree.Selected().path.Equal(current) {
+			break
+		}
+	}
+
+	selectPath(tree, state.cursor)
+}
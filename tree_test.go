@@ -0,0 +1,201 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+// visiblePaths returns paths of visible nodes, closing brackets are marked
+// with trailing /, collapsed containers with trailing +.
+func visiblePaths(tree *hierachy.Hierachy[entry]) []string {
+	var res []string
+	tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		s := item.Value.path.String()
+		switch {
+		case item.Value.closing:
+			s += "/"
+		case item.HasChildren && item.IsCollapsed:
+			s += "+"
+		}
+		res = append(res, s)
+		return true
+	})
+	return res
+}
+
+func TestCollapseRecursively(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"a": {"b": {"c": 1}}, "d": [1, [2]]}`
+	for name, tc := range map[string]struct {
+		cursor jsonPath
+		levels int
+		want   []string
+	}{
+		"root": {
+			cursor: jsonPath{},
+			levels: 1,
+			want:   []string{".", ".a+", ".d+", "./"},
+		},
+		"root two levels": {
+			cursor: jsonPath{},
+			levels: 2,
+			want:   []string{".", ".a", ".a.b+", ".a/", ".d", ".d[0]", ".d[1]+", ".d/", "./"},
+		},
+		"subtree": {
+			cursor: jsonPath{"a"},
+			levels: 1,
+			want:   []string{".", ".a", ".a.b+", ".a/", ".d", ".d[0]", ".d[1]", ".d[1][0]", ".d[1]/", ".d/", "./"},
+		},
+		"zero levels": {
+			cursor: jsonPath{"d"},
+			levels: 0,
+			want:   []string{".", ".a", ".a.b", ".a.b.c", ".a.b/", ".a/", ".d+", "./"},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := newHierachy(fromJSON(mustJSON(doc)))
+			selectPath(tree, tc.cursor)
+			collapseRecursively(tree, tc.levels)
+			if got := visiblePaths(tree); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("visible = %q, want %q", got, tc.want)
+			}
+			if got := tree.Selected().path; !got.Equal(tc.cursor) {
+				t.Errorf("cursor = %s, want %s", got, tc.cursor)
+			}
+		})
+	}
+}
+
+func TestRestoreState(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		before, after string
+		collapse      []jsonPath
+		cursor        jsonPath
+		want          []string
+		wantCursor    jsonPath
+	}{
+		"same document": {
+			before:     `{"a": {"b": 1}, "c": [1, 2]}`,
+			after:      `{"a": {"b": 1}, "c": [1, 2]}`,
+			collapse:   []jsonPath{{"a"}},
+			cursor:     jsonPath{"c", 1},
+			want:       []string{".", ".a+", ".c", ".c[0]", ".c[1]", ".c/", "./"},
+			wantCursor: jsonPath{"c", 1},
+		},
+		"changed document": {
+			before:     `{"a": {"b": 1}, "c": [1, 2]}`,
+			after:      `{"a": {"b": 2, "x": 3}, "c": [1, 2, 3], "d": {"e": 4}}`,
+			collapse:   []jsonPath{{"a"}},
+			cursor:     jsonPath{"c", 1},
+			want:       []string{".", ".a+", ".c", ".c[0]", ".c[1]", ".c[2]", ".c/", ".d", ".d.e", ".d/", "./"},
+			wantCursor: jsonPath{"c", 1},
+		},
+		"cursor removed": {
+			before:   `{"a": {"b": 1}, "c": [1, 2]}`,
+			after:    `{"a": {"b": 1}, "c": [1]}`,
+			collapse: []jsonPath{{"c"}},
+			cursor:   jsonPath{"c", 1},
+			// collapsed parent is expanded while looking for cursor
+			want:       []string{".", ".a", ".a.b", ".a/", ".c", ".c[0]", ".c/", "./"},
+			wantCursor: jsonPath{"c"},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := newHierachy(fromJSON(mustJSON(tc.before)))
+			selectPath(tree, tc.cursor)
+			state := saveState(tree)
+			for _, p := range tc.collapse {
+				state.collapsed[p.String()] = struct{}{}
+			}
+
+			tree = newHierachy(fromJSON(mustJSON(tc.after)))
+			restoreState(tree, state)
+			if got := visiblePaths(tree); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("visible = %q, want %q", got, tc.want)
+			}
+			if got := tree.Selected().path; !got.Equal(tc.wantCursor) {
+				t.Errorf("cursor = %s, want %s", got, tc.wantCursor)
+			}
+		})
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"a": {"b": 1, "c": [1, 2, 3]}, "d": 2, "e": [4]}`
+	for name, tc := range map[string]struct {
+		cursor jsonPath
+		keys   string
+		// want is path of selected node, with trailing / on closing bracket
+		want string
+	}{
+		"next sibling":              {jsonPath{"a"}, "J", ".d"},
+		"next sibling count":        {jsonPath{"a"}, "2J", ".e"},
+		"next sibling of last":      {jsonPath{"e"}, "J", ".e"},
+		"next sibling in array":     {jsonPath{"a", "c", 0}, "J", ".a.c[1]"},
+		"previous sibling":          {jsonPath{"d"}, "K", ".a"},
+		"previous sibling of first": {jsonPath{"a"}, "K", ".a"},
+		"parent":                    {jsonPath{"a", "c", 1}, "p", ".a.c"},
+		"first child":               {jsonPath{"a"}, "[", ".a.b"},
+		"last child":                {jsonPath{"a"}, "]", ".a.c"},
+		"child of leaf":             {jsonPath{"a", "b"}, "]", ".a.b"},
+		"closing bracket":           {jsonPath{"a"}, "%", ".a/"},
+		"opening bracket":           {jsonPath{"a"}, "%%", ".a"},
+		"bracket of leaf":           {jsonPath{"d"}, "%", ".d"},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := prepareDocument(t, mustJSON(doc))
+			selectPath(m.tree, tc.cursor)
+			send(m, keys(tc.keys)...)
+
+			selected := m.tree.Selected()
+			got := selected.path.String()
+			if selected.closing {
+				got += "/"
+			}
+			if got != tc.want {
+				t.Errorf("cursor after %q = %s, want %s", tc.keys, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFoldKeys(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"a": {"b": {"c": 1}}, "d": [1, [2]]}`
+	for name, tc := range map[string]struct {
+		keys string
+		want []string
+	}{
+		"collapse all":  {"E", []string{".", ".a+", ".d+", "./"}},
+		"expand all":    {"Ee", []string{".", ".a", ".a.b", ".a.b.c", ".a.b/", ".a/", ".d", ".d[0]", ".d[1]", ".d[1][0]", ".d[1]/", ".d/", "./"}},
+		"fold to level": {"2E", []string{".", ".a", ".a.b+", ".a/", ".d", ".d[0]", ".d[1]+", ".d/", "./"}},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := prepareDocument(t, mustJSON(doc))
+			send(m, keys(tc.keys)...)
+			if got := visiblePaths(m.tree); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("visible after %q = %q, want %q", tc.keys, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// msgWatch is result of single run of watched command and query pipeline
+// over its output, results holds result of each stage and streams their
+// results if there is more than one of them.
+type msgWatch struct {
+	original any
+	pipeline []string
+	results  []any
+	streams  []*resultStream
+	err      error
+}
+
+// runWatched runs shell command and parses its output as JSON document.
+func runWatched(command string) (any, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("watch: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("watch: %w", err)
+	}
+
+	var v any
+	if err := json.Unmarshal(stdout.Bytes(), &v); err != nil {
+		return nil, fmt.Errorf("watch: parse output: %w", err)
+	}
+	return v, nil
+}
+
+// cmdWatch reruns watched command and query pipeline over its output after
+// interval. Stages are replayed fetching as many results as they have shown.
+func (m *model) cmdWatch() tea.Cmd {
+	command, pipeline, timeout, limit := m.watchCommand, m.pipeline(), m.queryTimeout, m.queryLimit
+	counts := make([]int, len(pipeline))
+	for i, s := range append(m.stages[:len(m.stages):len(m.stages)], m.stage) {
+		if s.stream != nil {
+			counts[i] = s.stream.count
+		}
+	}
+	return tea.Tick(m.watchInterval, func(time.Time) tea.Msg {
+		v, err := runWatched(command)
+		if err != nil {
+			return msgWatch{
+				original: nil,
+				pipeline: pipeline,
+				results:  nil,
+				streams:  nil,
+				err:      err,
+			}
+		}
+
+		results := make([]any, 0, len(pipeline))
+		streams := make([]*resultStream, 0, len(pipeline))
+		input := v
+		for i, program := range pipeline {
+			var s *resultStream
+			input, s, err = replayStream(program, input, counts[i], timeout, limit)
+			if err != nil {
+				break
+			}
+			results = append(results, input)
+			streams = append(streams, s)
+		}
+		return msgWatch{
+			original: v,
+			pipeline: pipeline,
+			results:  results,
+			streams:  streams,
+			err:      err,
+		}
+	})
+}
+
+// markDiff marks nodes of tree built from new document with changes relative
+// to previous document and puts removed values back as nodes. Containers are
+// marked changed if anything inside them has changed. Returns whether node
+// differs from prev.
+func markDiff(node *hierachy.Node[entry], prev any) bool {
+	switch prev := prev.(type) {
+	case decodedString:
+		return markDiff(node, prev.value)
+	case map[string]any:
+		if node.Value.kind != elemKindObject {
+			node.Value.diff = diffChanged
+			return true
+		}
+
+		changed := false
+		seen := make(map[string]struct{}, len(node.Children))
+		for i := range node.Children {
+			child := &node.Children[i]
+			k := child.Value.path[len(child.Value.path)-1].(string)
+			seen[k] = struct{}{}
+			if old, ok := prev[k]; ok {
+				changed = markDiff(child, old) || changed
+			} else {
+				markAll(child, diffAdded)
+				changed = true
+			}
+		}
+		for k, old := range prev {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+
+			removed := fromJSONAt(node.Value.path.Key(k), old)
+			markAll(&removed, diffRemoved)
+			node.Children = append(node.Children, removed)
+			changed = true
+		}
+		depth := len(node.Value.path)
+		sort.SliceStable(node.Children, func(i, j int) bool {
+			return node.Children[i].Value.path[depth].(string) < node.Children[j].Value.path[depth].(string)
+		})
+		if changed {
+			node.Value.diff = diffChanged
+		}
+		return changed
+	case []any:
+		if node.Value.kind != elemKindArray {
+			node.Value.diff = diffChanged
+			return true
+		}
+
+		changed := false
+		for i := range node.Children {
+			if i < len(prev) {
+				changed = markDiff(&node.Children[i], prev[i]) || changed
+			} else {
+				markAll(&node.Children[i], diffAdded)
+				changed = true
+			}
+		}
+		for i := len(node.Children); i < len(prev); i++ {
+			removed := fromJSONAt(node.Value.path.Index(i), prev[i])
+			markAll(&removed, diffRemoved)
+			node.Children = append(node.Children, removed)
+			changed = true
+		}
+		if changed {
+			node.Value.diff = diffChanged
+		}
+		return changed
+	default:
+		old := fromJSON(prev).Value
+		if old.kind != node.Value.kind || old.value != node.Value.value {
+			node.Value.diff = diffChanged
+			return true
+		}
+		return false
+	}
+}
+
+func markAll(node *hierachy.Node[entry], diff diffKind) {
+	node.Value.diff = diff
+	for i := range node.Children {
+		markAll(&node.Children[i], diff)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+func TestMarkDiff(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		prev, next string
+		changed    bool
+		want       map[string]diffKind
+	}{
+		"same": {
+			prev:    `{"a": 1, "b": [1, 2]}`,
+			next:    `{"a": 1, "b": [1, 2]}`,
+			changed: false,
+			want:    map[string]diffKind{},
+		},
+		"object": {
+			prev:    `{"a": 1, "b": 2, "d": 5}`,
+			next:    `{"a": 1, "b": 3, "c": 4}`,
+			changed: true,
+			want: map[string]diffKind{
+				".":  diffChanged,
+				".b": diffChanged,
+				".c": diffAdded,
+				".d": diffRemoved,
+			},
+		},
+		"array": {
+			prev:    `[1, 2, 3]`,
+			next:    `[1, 5]`,
+			changed: true,
+			want: map[string]diffKind{
+				".":    diffChanged,
+				".[1]": diffChanged,
+				".[2]": diffRemoved,
+			},
+		},
+		"nested": {
+			prev:    `{"a": {"b": [1]}, "c": 1}`,
+			next:    `{"a": {"b": [1, {"x": 1}]}, "c": 1}`,
+			changed: true,
+			want: map[string]diffKind{
+				".":         diffChanged,
+				".a":        diffChanged,
+				".a.b":      diffChanged,
+				".a.b[1]":   diffAdded,
+				".a.b[1].x": diffAdded,
+			},
+		},
+		"type": {
+			prev:    `{"a": [1]}`,
+			next:    `{"a": {"0": 1}}`,
+			changed: true,
+			// children of replaced container are not compared
+			want: map[string]diffKind{
+				".":  diffChanged,
+				".a": diffChanged,
+			},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := fromJSON(mustJSON(tc.next))
+			if changed := markDiff(&tree, mustJSON(tc.prev)); changed != tc.changed {
+				t.Errorf("changed = %v, want %v", changed, tc.changed)
+			}
+
+			got := map[string]diffKind{}
+			var walk func(node hierachy.Node[entry])
+			walk = func(node hierachy.Node[entry]) {
+				if node.Value.diff != diffNone {
+					got[node.Value.path.String()] = node.Value.diff
+				}
+				for _, child := range node.Children {
+					walk(child)
+				}
+			}
+			walk(tree)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("diff = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": 1, "b": [1, 2], "c": {"d": 1}}`))
+	m.watchCommand = `echo '{"a": 1, "b": [1, 3, 4], "c": {"d": 1}}'`
+	m.watchInterval = time.Millisecond
+	selectPath(m.tree, jsonPath{"b", 1})
+
+	var cmds []tea.Cmd
+	m.Update(m.cmdWatch()(), func(c ...tea.Cmd) { cmds = append(cmds, c...) })
+	if len(cmds) == 0 {
+		t.Fatal("command is not run again")
+	}
+
+	got := map[string]diffKind{}
+	m.tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		if item.Value.diff != diffNone {
+			got[item.Value.path.String()] = item.Value.diff
+		}
+		return true
+	})
+	want := map[string]diffKind{
+		".":     diffChanged,
+		".b":    diffChanged,
+		".b[1]": diffChanged,
+		".b[2]": diffAdded,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff = %v, want %v", got, want)
+	}
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{"b", 1}) {
+		t.Errorf("cursor = %s, want .b[1]", got)
+	}
+}
+
+func TestWatchError(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": 1}`))
+	m.watchCommand = `echo '{"a":'`
+	m.watchInterval = time.Millisecond
+
+	m.Update(m.cmdWatch()(), func(...tea.Cmd) {})
+	if m.queryError == "" {
+		t.Error("error of command output is not shown")
+	}
+	if !reflect.DeepEqual(m.result, mustJSON(`{"a": 1}`)) {
+		t.Errorf("result = %v, want previous document", m.result)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rprtr258/fun"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+// minValueWidth is least number of columns kept for values. Deeper nesting is
+// not indented further and values after long keys are moved to next line.
+const minValueWidth = 16
+
+// scrollStep is number of columns lines are scrolled horizontally at once.
+const scrollStep = 4
+
+// layout is placement of node on screen of some width.
+type layout struct {
+	indent int      // column of key or value
+	col    int      // column of value chunks
+	skip   int      // lines before first chunk, 1 if value was moved after key
+	chunks []string // parts of value, each on its own line
+	end    int      // column after end of first line
+}
+
+func (l layout) height() int {
+	return l.skip + len(l.chunks)
+}
+
+// layoutOf places node on screen of given width. Leaf values are wrapped to
+// several lines aligned under first one, if wrapping is on. Otherwise line is
+// kept whole to be scrolled horizontally.
+func (m *model) layoutOf(item hierachy.IterItem[entry], width int) layout {
+	// closing bracket is child of its container, but aligned with it
+	depth := fun.IF(item.Value.closing, item.Depth-1, item.Depth)
+	indent := 2 * depth
+	if m.wrap {
+		indent = min(indent, max(0, width-minValueWidth))
+	}
+	col := indent
+	if item.Value.isKey {
+		col += runewidth.StringWidth(item.Value.key) + len(": ")
+	}
+	if item.Value.decoded != "" {
+		col += runewidth.StringWidth(item.Value.decoded) + len(" ")
+	}
+
+	value := item.Value.value
+	res := layout{
+		indent: indent,
+		col:    col,
+		skip:   0,
+		chunks: []string{value},
+		end:    col + runewidth.StringWidth(value),
+	}
+	switch {
+	case item.HasChildren && item.IsCollapsed:
+		preview, count := collapsedText(item.Value, width-col)
+		res.end = col + runewidth.StringWidth("{"+preview+count+"} "+formatSize(item.Value.size))
+	case item.HasChildren:
+		res.end = col + len("{")
+	}
+	if item.HasChildren || !m.wrap || width <= 0 || res.end <= width {
+		return res
+	}
+
+	if width-col < minValueWidth {
+		res.col = min(indent+2, max(0, width-minValueWidth))
+		res.skip = 1
+	}
+	res.chunks = chunkString(value, width-res.col)
+	res.end = width
+	return res
+}
+
+// chunkString splits s into parts at most width columns wide.
+func chunkString(s string, width int) []string {
+	var chunks []string
+	var sb strings.Builder
+	w := 0
+	for _, c := range s {
+		cw := runewidth.RuneWidth(c)
+		if w+cw > width && w > 0 {
+			chunks = append(chunks, sb.String())
+			sb.Reset()
+			w = 0
+		}
+		sb.WriteRune(c)
+		w += cw
+	}
+	return append(chunks, sb.String())
+}
+
+// lineHeights returns function giving number of screen lines taken by i-th
+// visible node of window. Heights are computed on demand and remembered.
+func (m *model) lineHeights(w window) func(int) int {
+	width := m.jsonWidth()
+	cache := map[int]int{}
+	return func(i int) int {
+		h, ok := cache[i]
+		if !ok {
+			h = m.layoutOf(w.item(i), width).height()
+			cache[i] = h
+		}
+		return h
+	}
+}
+
+// writeAt writes s starting at column x of vb, part of s left of vb is
+// skipped. Returns column after s.
+func writeAt(vb tea.Viewbox, x int, s string) int {
+	end := x + runewidth.StringWidth(s)
+	for x < 0 && s != "" {
+		c, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		x += runewidth.RuneWidth(c)
+	}
+	if s == "" || x >= vb.Width {
+		return end
+	}
+
+	vb.PaddingLeft(x).WriteLine(s)
+	return end
+}
+
+// toggleWrap switches between wrapped and truncated lines.
+func (m *model) toggleWrap() {
+	m.wrap = !m.wrap
+	m.colOffset = 0
+}
+
+// scrollColumns scrolls lines horizontally, keeping some part of widest
+// visible line on screen.
+func (m *model) scrollColumns(columns int) {
+	if m.wrap {
+		return
+	}
+
+	width, widest := m.jsonWidth(), 0
+	m.tree.Iter(func(item hierachy.IterItem[entry]) bool {
+		widest = max(widest, m.layoutOf(item, width).end)
+		return true
+	})
+	m.colOffset = fun.Clamp(m.colOffset+columns, 0, max(0, widest-width+1))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rprtr258/tea/components/headless/hierachy"
+)
+
+func TestChunkString(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		s     string
+		width int
+		want  []string
+	}{
+		"fits":       {"abc", 5, []string{"abc"}},
+		"exact":      {"abcdef", 3, []string{"abc", "def"}},
+		"remainder":  {"abcdefg", 3, []string{"abc", "def", "g"}},
+		"empty":      {"", 3, []string{""}},
+		"wide runes": {"日本語", 4, []string{"日本", "語"}},
+		"too narrow": {"a日", 1, []string{"a", "日"}},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := chunkString(tc.s, tc.width); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("chunkString(%q, %d) = %q, want %q", tc.s, tc.width, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLayoutOf(t *testing.T) {
+	t.Parallel()
+
+	long := `"` + strings.Repeat("x", 18) + `"`
+	for name, tc := range map[string]struct {
+		item  hierachy.IterItem[entry]
+		wrap  bool
+		width int
+		want  layout
+	}{
+		"leaf": {
+			item:  hierachy.IterItem[entry]{Depth: 1, Value: entry{kind: elemKindString, isKey: true, key: `"a"`, value: `"xy"`}},
+			wrap:  true,
+			width: 80,
+			want:  layout{indent: 2, col: 7, skip: 0, chunks: []string{`"xy"`}, end: 11},
+		},
+		"wrapped after key": {
+			item:  hierachy.IterItem[entry]{Depth: 1, Value: entry{kind: elemKindString, isKey: true, key: `"a"`, value: long}},
+			wrap:  true,
+			width: 20,
+			want:  layout{indent: 2, col: 4, skip: 1, chunks: []string{long[:16], long[16:]}, end: 20},
+		},
+		"not wrapped": {
+			item:  hierachy.IterItem[entry]{Depth: 1, Value: entry{kind: elemKindString, isKey: true, key: `"a"`, value: long}},
+			wrap:  false,
+			width: 20,
+			want:  layout{indent: 2, col: 7, skip: 0, chunks: []string{long}, end: 27},
+		},
+		"deep indent": {
+			item:  hierachy.IterItem[entry]{Depth: 40, Value: entry{kind: elemKindNumber, value: "1"}},
+			wrap:  true,
+			width: 80,
+			want:  layout{indent: 64, col: 64, skip: 0, chunks: []string{"1"}, end: 65},
+		},
+		"expanded": {
+			item:  hierachy.IterItem[entry]{Depth: 1, HasChildren: true, Value: entry{kind: elemKindObject, isKey: true, key: `"o"`, value: "{"}},
+			wrap:  true,
+			width: 80,
+			want:  layout{indent: 2, col: 7, skip: 0, chunks: []string{"{"}, end: 8},
+		},
+		"collapsed": {
+			item: hierachy.IterItem[entry]{Depth: 1, HasChildren: true, IsCollapsed: true, Value: entry{
+				kind: elemKindObject, isKey: true, key: `"o"`, value: "{",
+				size: 15, count: 2, preview: []string{`"a": 1`, `"b": 2`},
+			}},
+			wrap:  true,
+			width: 80,
+			want:  layout{indent: 2, col: 7, skip: 0, chunks: []string{"{"}, end: 7 + len(`{"a": 1, "b": 2} 15 B`)},
+		},
+		"closing bracket": {
+			item:  hierachy.IterItem[entry]{Depth: 2, Value: entry{kind: elemKindObject, value: "}", closing: true}},
+			wrap:  true,
+			width: 80,
+			want:  layout{indent: 2, col: 2, skip: 0, chunks: []string{"}"}, end: 3},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &model{wrap: tc.wrap}
+			if got := m.layoutOf(tc.item, tc.width); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("layoutOf() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScrollColumns(t *testing.T) {
+	t.Parallel()
+
+	doc := map[string]any{"a": strings.Repeat("x", 200)}
+	for name, tc := range map[string]struct {
+		keys   string
+		wrap   bool
+		offset int
+	}{
+		"wrap off":      {"zw", false, 0},
+		"right":         {"zwzl", false, scrollStep},
+		"right count":   {"zw3zl", false, 3 * scrollStep},
+		"left":          {"zwzlzlzh", false, scrollStep},
+		"left of start": {"zwzh", false, 0},
+		"aliases":       {"zw>>>", false, 3 * scrollStep},
+		"end of line":   {"zw99zl", false, 130},
+		"wrap on":       {"zl", true, 0},
+		"wrap again":    {"zwzlzw", true, 0},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := prepareDocument(t, doc)
+			send(m, keys(tc.keys)...)
+			if m.wrap != tc.wrap || m.colOffset != tc.offset {
+				t.Errorf("after %q wrap = %v, offset = %d, want %v, %d", tc.keys, m.wrap, m.colOffset, tc.wrap, tc.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"github.com/mattn/go-runewidth"
+	"github.com/rprtr258/tea"
+	"github.com/rprtr258/tea/styles"
+)
+
+// breadcrumbSeparator separates roots of zoom levels in header.
+const breadcrumbSeparator = " › "
+
+// zoomLevel is view of document before zooming into one of its subtrees.
+type zoomLevel struct {
+	root   jsonPath
+	state  treeState
+	offset int
+}
+
+// viewed returns part of v shown in view, which is whole v unless zoomed.
+func (m *model) viewed(v any) (any, bool) {
+	v = m.decodeStrings(v)
+	if len(m.root) == 0 {
+		return v, true
+	}
+	return valueAt(v, m.root)
+}
+
+// zoomIn makes selected object or array root of view.
+func (m *model) zoomIn() {
+	selected := m.tree.Selected()
+	if selected.kind != elemKindObject && selected.kind != elemKindArray {
+		return
+	}
+
+	root := selected.path.Value()
+	if root.Equal(m.root) {
+		return
+	}
+
+	m.zoom = append(m.zoom, zoomLevel{
+		root:   m.root,
+		state:  saveState(m.tree),
+		offset: m.offset,
+	})
+	m.root = root
+	m.redoSearch()
+	m.tree = newHierachy(m.newTree(m.result))
+	m.offset = 0
+}
+
+// zoomOut returns to view before zooming into level-th root, restoring its
+// cursor and folds.
+func (m *model) zoomOut(level int) {
+	if level < 0 || level >= len(m.zoom) {
+		return
+	}
+
+	prev := m.zoom[level]
+	m.zoom = m.zoom[:level]
+	m.root = prev.root
+	m.redoSearch()
+	m.tree = newHierachy(m.newTree(m.result))
+	restoreState(m.tree, prev.state)
+	m.offset = prev.offset
+}
+
+// resetZoom shows whole document again, e.g. after query has changed it.
+func (m *model) resetZoom() {
+	m.root, m.zoom = nil, nil
+}
+
+// headerHeight is number of lines above JSON view taken by query pipeline and
+// zoom breadcrumbs. In split view both panes share it, so that they have the
+// same height.
+func (m *model) headerHeight() int {
+	h := 0
+	if len(m.stages) > 0 {
+		h++
+	}
+	if len(m.zoom) > 0 || m.split && len(m.other.zoom) > 0 {
+		h++
+	}
+	return h
+}
+
+// breadcrumbs returns roots of zoom levels, current one is the last.
+func (m *model) breadcrumbs() []string {
+	res := make([]string, 0, len(m.zoom)+1)
+	for _, level := range m.zoom {
+		res = append(res, level.root.String())
+	}
+	return append(res, m.root.String())
+}
+
+func (m *model) viewBreadcrumbs(vb tea.Viewbox) {
+	crumbs := m.breadcrumbs()
+	for i, crumb := range crumbs {
+		if i > 0 {
+			vb = vb.Styled(styles.Style{}.Foreground(currentTheme.Preview)).WriteLineX(breadcrumbSeparator)
+		}
+		vb = vb.Styled(styles.Style{}.Foreground(currentTheme.Key)).WriteLineX(crumb)
+	}
+}
+
+// breadcrumbLevel returns zoom level whose root is shown at column x of header.
+func (m *model) breadcrumbLevel(x int) (int, bool) {
+	return crumbAt(m.breadcrumbs(), breadcrumbSeparator, x)
+}
+
+// crumbAt returns index of crumb shown at column x, when crumbs are written
+// one after another with separator between them.
+func crumbAt(crumbs []string, separator string, x int) (int, bool) {
+	for i, crumb := range crumbs {
+		if i > 0 {
+			x -= runewidth.StringWidth(separator)
+		}
+		if x < 0 {
+			return 0, false
+		}
+
+		x -= runewidth.StringWidth(crumb)
+		if x < 0 {
+			return i, true
+		}
+	}
+	return 0, false
+}
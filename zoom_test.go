@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestZoom(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": {"b": {"c": 1}, "d": [1, 2]}, "e": {"f": 1}}`))
+	selectPath(m.tree, jsonPath{"e"})
+	send(m, keys("h")...)
+	selectPath(m.tree, jsonPath{"a"})
+	send(m, keys("+")...)
+	selectPath(m.tree, jsonPath{"a", "d"})
+	send(m, keys("+")...)
+
+	want := []string{".a.d", ".a.d[0]", ".a.d[1]", ".a.d/"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible in zoomed subtree = %q, want %q", got, want)
+	}
+	if got, want := m.breadcrumbs(), []string{".", ".a", ".a.d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("breadcrumbs() = %q, want %q", got, want)
+	}
+
+	send(m, keys("-")...)
+	want = []string{".a", ".a.b", ".a.b.c", ".a.b/", ".a.d", ".a.d[0]", ".a.d[1]", ".a.d/", ".a/"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after zooming out = %q, want %q", got, want)
+	}
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{"a", "d"}) {
+		t.Errorf("cursor after zooming out = %s, want .a.d", got)
+	}
+
+	send(m, keys("-")...)
+	want = []string{".", ".a", ".a.b", ".a.b.c", ".a.b/", ".a.d", ".a.d[0]", ".a.d[1]", ".a.d/", ".a/", ".e+", "./"}
+	if got := visiblePaths(m.tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("visible after zooming out to document = %q, want %q", got, want)
+	}
+	if got := m.tree.Selected().path; !got.Equal(jsonPath{"a"}) {
+		t.Errorf("cursor after zooming out to document = %s, want .a", got)
+	}
+	if len(m.zoom) != 0 || len(m.root) != 0 {
+		t.Errorf("zoom = %v, root = %s after zooming out to document", m.zoom, m.root)
+	}
+}
+
+func TestZoomLeaf(t *testing.T) {
+	t.Parallel()
+
+	m := prepareDocument(t, mustJSON(`{"a": 1}`))
+	selectPath(m.tree, jsonPath{"a"})
+	send(m, keys("+")...)
+	if len(m.zoom) != 0 {
+		t.Errorf("zoomed into %s, want only objects and arrays zoomed into", m.root)
+	}
+}
+
+func TestCrumbAt(t *testing.T) {
+	t.Parallel()
+
+	// crumbs are shown as ".a › .b › 日本"
+	crumbs := []string{".a", ".b", "日本"}
+	for name, tc := range map[string]struct {
+		x    int
+		want int
+		ok   bool
+	}{
+		"first":             {0, 0, true},
+		"end of first":      {1, 0, true},
+		"separator":         {2, 0, false},
+		"end of separator":  {4, 0, false},
+		"second":            {5, 1, true},
+		"wide runes":        {10, 2, true},
+		"end of wide runes": {13, 2, true},
+		"past end":          {14, 0, false},
+		"negative":          {-1, 0, false},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := crumbAt(crumbs, breadcrumbSeparator, tc.x)
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("crumbAt(%d) = %d, %v, want %d, %v", tc.x, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}